@@ -0,0 +1,191 @@
+package transfermanager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// chunkStat is one downloaded chunk's measured throughput, fed to the
+// adaptiveController after each part completes.
+type chunkStat struct {
+	bytes    int64
+	duration time.Duration
+}
+
+// adaptiveController tunes the number of in-flight part downloads and the
+// part size itself to the throughput actually being observed, instead of
+// requiring callers to hand-tune Concurrency/PartSizeBytes for each object
+// size and network path.
+//
+// It keeps an EWMA of aggregate throughput and, on each reported sample,
+// either grows the worker count (throughput still rising), backs it off
+// (latency spiked or throughput plateaued for a few samples in a row), or
+// holds steady during a cooldown after backing off before probing upward
+// again. Part size grows similarly when individual GETs complete quickly,
+// which indicates HTTP overhead rather than bandwidth is the bottleneck.
+type adaptiveController struct {
+	min, max int32
+	workers  int32 // current target worker count, accessed atomically
+
+	minPartSize, maxPartSize int64
+	partSize                 int64 // current target part size, accessed atomically
+
+	mu             sync.Mutex
+	ewmaThroughput float64 // bytes/sec
+	plateauSamples int
+	cooldownUntil  time.Time
+	lastLatency    time.Duration
+	sem            chan struct{}
+
+	// pendingDrain counts slots backOff could not take out of sem because
+	// workers held them; release retires that many instead of returning
+	// them. Accessed atomically.
+	pendingDrain int32
+}
+
+const (
+	adaptiveEWMAAlpha          = 0.3
+	adaptivePlateauThreshold   = 0.05 // 5%
+	adaptivePlateauSampleLimit = 3
+	adaptiveCooldown           = 2 * time.Second
+	adaptiveLatencySpikeFactor = 2.0
+)
+
+func newAdaptiveController(minWorkers, maxWorkers int, minPartSize, maxPartSize int64) *adaptiveController {
+	if maxWorkers < minWorkers {
+		maxWorkers = minWorkers
+	}
+	c := &adaptiveController{
+		min:         int32(minWorkers),
+		max:         int32(maxWorkers),
+		workers:     int32(minWorkers),
+		minPartSize: minPartSize,
+		maxPartSize: maxPartSize,
+		partSize:    minPartSize,
+		sem:         make(chan struct{}, maxWorkers),
+	}
+	for i := 0; i < minWorkers; i++ {
+		c.sem <- struct{}{}
+	}
+	return c
+}
+
+// acquire blocks until a worker slot is available under the current target
+// concurrency, or ctx is done.
+func (c *adaptiveController) acquire(ctx context.Context) error {
+	select {
+	case <-c.sem:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns a worker slot, or retires it if backOff has lowered the
+// target below the number of slots in use.
+func (c *adaptiveController) release() {
+	for {
+		p := atomic.LoadInt32(&c.pendingDrain)
+		if p <= 0 {
+			break
+		}
+		if atomic.CompareAndSwapInt32(&c.pendingDrain, p, p-1) {
+			return
+		}
+	}
+	select {
+	case c.sem <- struct{}{}:
+	default:
+	}
+}
+
+// currentPartSize returns the part size new chunks should be queued with.
+func (c *adaptiveController) currentPartSize() int64 {
+	return atomic.LoadInt64(&c.partSize)
+}
+
+// report feeds a completed chunk's throughput sample into the controller and
+// adjusts target concurrency and part size accordingly.
+func (c *adaptiveController) report(stat chunkStat) {
+	if stat.duration <= 0 {
+		return
+	}
+	throughput := float64(stat.bytes) / stat.duration.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.ewmaThroughput
+	if prev == 0 {
+		c.ewmaThroughput = throughput
+	} else {
+		c.ewmaThroughput = adaptiveEWMAAlpha*throughput + (1-adaptiveEWMAAlpha)*prev
+	}
+
+	latencySpiked := c.lastLatency > 0 && stat.duration > time.Duration(float64(c.lastLatency)*adaptiveLatencySpikeFactor)
+	c.lastLatency = stat.duration
+
+	now := time.Now()
+	growing := prev > 0 && c.ewmaThroughput > prev*(1+adaptivePlateauThreshold)
+	plateaued := prev > 0 && c.ewmaThroughput <= prev*(1+adaptivePlateauThreshold)
+
+	switch {
+	case latencySpiked:
+		c.backOff(now)
+	case plateaued:
+		c.plateauSamples++
+		if c.plateauSamples >= adaptivePlateauSampleLimit {
+			c.backOff(now)
+			c.plateauSamples = 0
+		}
+	case growing && now.After(c.cooldownUntil):
+		c.plateauSamples = 0
+		c.growWorkers()
+		c.growPartSize(stat)
+	}
+}
+
+func (c *adaptiveController) backOff(now time.Time) {
+	cur := atomic.LoadInt32(&c.workers)
+	next := cur / 2
+	if next < c.min {
+		next = c.min
+	}
+	if next < cur {
+		atomic.StoreInt32(&c.workers, next)
+		for i := cur - next; i > 0; i-- {
+			select {
+			case <-c.sem:
+			default:
+				atomic.AddInt32(&c.pendingDrain, 1)
+			}
+		}
+	}
+	c.cooldownUntil = now.Add(adaptiveCooldown)
+}
+
+func (c *adaptiveController) growWorkers() {
+	cur := atomic.LoadInt32(&c.workers)
+	if cur >= c.max {
+		return
+	}
+	atomic.AddInt32(&c.workers, 1)
+	c.release()
+}
+
+func (c *adaptiveController) growPartSize(stat chunkStat) {
+	// A part that completed within 500ms, whatever its size, suggests the part
+	// is small enough that per-request overhead dominates; doubling the
+	// part size (bounded by maxPartSize) amortizes that overhead.
+	if stat.duration > 500*time.Millisecond {
+		return
+	}
+	cur := atomic.LoadInt64(&c.partSize)
+	next := cur * 2
+	if next > c.maxPartSize {
+		next = c.maxPartSize
+	}
+	atomic.StoreInt64(&c.partSize, next)
+}
@@ -0,0 +1,235 @@
+package transfermanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// WriterAtFactory builds the destination sink for a single object downloaded
+// by DownloadDirectory. The returned io.Closer is always closed once the
+// object has finished downloading (or failed), regardless of any error
+// returned alongside it.
+type WriterAtFactory func(key string) (io.WriterAt, io.Closer, error)
+
+// DownloadDirectoryInput represents a request to the DownloadDirectory() call.
+// It lists objects under Bucket/Prefix and downloads each of them into
+// Destination on the local filesystem.
+type DownloadDirectoryInput struct {
+	// Bucket the objects are downloaded from.
+	Bucket string
+
+	// Prefix restricts the listing to keys beginning with this value. An empty
+	// Prefix downloads the entire bucket.
+	Prefix string
+
+	// Destination is the local directory the objects are written under. Keys
+	// are translated into relative filesystem paths, splitting on "/".
+	Destination string
+
+	// Filter, when non-nil, is called with each listed key before it is
+	// downloaded. Returning false skips the object.
+	Filter func(key string) bool
+
+	// WriterAtFactory builds the sink for each object. When nil, objects are
+	// written to files under Destination, creating parent directories as
+	// needed.
+	WriterAtFactory WriterAtFactory
+
+	// FailFast stops queuing new downloads as soon as the first object fails.
+	// When false (the default) all other objects continue downloading and
+	// their errors are collected in DownloadDirectoryOutput.Errors.
+	FailFast bool
+
+	// DirectoryConcurrency controls how many objects are downloaded at once.
+	// This is independent of the per-object part Concurrency in Options. It
+	// defaults to Options.Concurrency when unset.
+	DirectoryConcurrency int
+}
+
+// DownloadDirectoryOutput represents a response from DownloadDirectory().
+type DownloadDirectoryOutput struct {
+	// ObjectsDownloaded is the number of objects successfully downloaded.
+	ObjectsDownloaded int
+
+	// Errors holds one entry per object that failed to download. Downloads
+	// are still attempted for every other object unless FailFast is set.
+	Errors []DownloadDirectoryObjectError
+}
+
+// DownloadDirectoryObjectError pairs a failed object key with the error that
+// caused its download to fail.
+type DownloadDirectoryObjectError struct {
+	Key string
+	Err error
+}
+
+func (e *DownloadDirectoryObjectError) Error() string {
+	return fmt.Sprintf("download %s: %v", e.Key, e.Err)
+}
+
+// DownloadDirectory downloads every object under Bucket/Prefix to a local
+// destination directory, paging through ListObjectsV2 and dispatching
+// per-object downloads across a bounded worker pool. Unlike DownloadObject,
+// the concurrency here controls the number of objects in flight at once; each
+// individual object download still honors the part-level Concurrency in
+// Options.
+//
+// Additional functional options can be provided to configure the individual
+// download. These options are copies of the original Options instance, the
+// client of which DownloadDirectory is called from. Modifying the options
+// will not impact the original Client and Options instance.
+func (c *Client) DownloadDirectory(ctx context.Context, input *DownloadDirectoryInput, opts ...func(*Options)) (*DownloadDirectoryOutput, error) {
+	options := c.options.Copy()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	concurrency := input.DirectoryConcurrency
+	if concurrency <= 0 {
+		concurrency = options.Concurrency
+	}
+
+	type job struct {
+		key string
+	}
+
+	jobs := make(chan job)
+	out := &DownloadDirectoryOutput{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var stopped bool
+
+	worker := func() {
+		defer wg.Done()
+		for j := range jobs {
+			if err := c.downloadDirectoryObject(ctx, input, j.key, opts...); err != nil {
+				mu.Lock()
+				out.Errors = append(out.Errors, DownloadDirectoryObjectError{Key: j.key, Err: err})
+				if input.FailFast {
+					stopped = true
+				}
+				mu.Unlock()
+				continue
+			}
+			mu.Lock()
+			out.ObjectsDownloaded++
+			mu.Unlock()
+		}
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go worker()
+	}
+
+	p := s3.NewListObjectsV2Paginator(options.S3, &s3.ListObjectsV2Input{
+		Bucket: aws.String(input.Bucket),
+		Prefix: aws.String(input.Prefix),
+	})
+
+	var listErr error
+listing:
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			listErr = err
+			break
+		}
+
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/") {
+				continue // directory marker, nothing to download
+			}
+			if input.Filter != nil && !input.Filter(key) {
+				continue
+			}
+
+			mu.Lock()
+			if stopped {
+				mu.Unlock()
+				break listing
+			}
+			mu.Unlock()
+
+			select {
+			case jobs <- job{key: key}:
+			case <-ctx.Done():
+				listErr = ctx.Err()
+				break listing
+			}
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if listErr != nil {
+		return out, listErr
+	}
+	if input.FailFast && len(out.Errors) > 0 {
+		return out, &out.Errors[0]
+	}
+	return out, nil
+}
+
+func (c *Client) downloadDirectoryObject(ctx context.Context, input *DownloadDirectoryInput, key string, opts ...func(*Options)) error {
+	relPath, err := safeRelPath(key)
+	if err != nil {
+		return err
+	}
+
+	var w io.WriterAt
+	var closer io.Closer
+	if input.WriterAtFactory != nil {
+		w, closer, err = input.WriterAtFactory(key)
+		if err != nil {
+			if closer != nil {
+				closer.Close()
+			}
+			return err
+		}
+	} else {
+		dest := filepath.Join(input.Destination, relPath)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		f, err := os.Create(dest)
+		if err != nil {
+			return err
+		}
+		w, closer = f, f
+	}
+	defer closer.Close()
+
+	_, err = c.DownloadObject(ctx, &DownloadObjectInput{
+		Bucket:   input.Bucket,
+		Key:      key,
+		WriterAt: w,
+	}, opts...)
+	return err
+}
+
+// safeRelPath translates an S3 key into a relative filesystem path, rejecting
+// keys that would escape the destination directory via ".." segments or that
+// are rooted with a leading "/".
+func safeRelPath(key string) (string, error) {
+	if strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("invalid object key %q: absolute paths are not allowed", key)
+	}
+
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid object key %q: escapes destination directory", key)
+	}
+
+	return cleaned, nil
+}
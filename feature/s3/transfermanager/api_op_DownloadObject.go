@@ -203,6 +203,47 @@ type DownloadObjectInput struct {
 	// [Server-Side Encryption (Using Customer-Provided Encryption Keys)]: https://docs.aws.amazon.com/AmazonS3/latest/dev/ServerSideEncryptionCustomerKeys.html
 	SSECustomerKeyMD5 string
 
+	// Checkpoint, when set, persists per-part progress so the download can
+	// resume the missing parts instead of restarting from scratch if it is
+	// interrupted and retried with the same Checkpoint. If the object's
+	// ETag, VersionID or size no longer match the saved state, the
+	// checkpoint is discarded and the download restarts from the beginning.
+	//
+	// Checkpoint only has an effect on the chunked, range-based download
+	// path (objects larger than PartSizeBytes); it is ignored for
+	// single-part downloads.
+	Checkpoint Checkpoint
+
+	// ProgressListener, when set, is notified of bytes read for this object
+	// as each part's response body is copied to WriterAt. It overrides
+	// Options.ProgressListener for this call only.
+	ProgressListener ProgressListener
+
+	// Progress, when set, is invoked with structured ProgressEvents as the
+	// download moves through each part's lifecycle (PartStarted, PartBytes,
+	// PartRetried, PartCompleted) and once the whole object finishes
+	// (DownloadCompleted). It overrides Options.Progress for this call only.
+	//
+	// Unlike ProgressListener, which only reports bytes read, Progress
+	// exposes per-part retry counts and timings, making it suitable for
+	// driving metrics or a Stats() style dashboard.
+	Progress func(ProgressEvent)
+
+	// ReadLimiter, when set, throttles the aggregate rate bytes are read
+	// for this object across all of its concurrent part downloads. It
+	// overrides Options.ReadLimiter for this call only. Callers downloading
+	// many objects at once (for example via DownloadDirectory) can share a
+	// single ReadLimiter across calls to cap total throughput.
+	ReadLimiter ReadLimiter
+
+	// RestoreOnDemand, when set, makes DownloadObject transparently restore
+	// an archived (Glacier / Deep Archive / Intelligent-Tiering Archive)
+	// object before downloading it, rather than failing with
+	// InvalidObjectState. DownloadObject blocks until the restore completes
+	// or the call's context is done, in which case it returns
+	// *ErrRestoreTimeout.
+	RestoreOnDemand *RestoreOnDemand
+
 	// Version ID used to reference a specific version of the object.
 	//
 	// By default, the GetObject operation returns the current version of an object.
@@ -552,6 +593,17 @@ type downloader struct {
 	written    int64
 	etag       string
 
+	checkpointState CheckpointState
+	partIndex       int64
+
+	checksumAcc checksumAccumulator
+
+	adaptive  *adaptiveController
+	scheduler RangeScheduler
+
+	stats  *statsRecorder
+	cancel context.CancelFunc
+
 	err error
 }
 
@@ -560,6 +612,16 @@ func (d *downloader) download(ctx context.Context) (*DownloadObjectOutput, error
 		return nil, fmt.Errorf("unable to initialize download: %w", err)
 	}
 
+	// Deriving a cancellable context lets setErr abort every other
+	// in-flight part's GetObject/body read as soon as the download gives
+	// up, instead of letting them run to completion only to be discarded.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.cancel = cancel
+
+	d.stats = newStatsRecorder()
+	downloadStart := time.Now()
+
 	clientOptions := []func(*s3.Options){
 		func(o *s3.Options) {
 			o.APIOptions = append(o.APIOptions,
@@ -568,6 +630,10 @@ func (d *downloader) download(ctx context.Context) (*DownloadObjectOutput, error
 			)
 		}}
 
+	if err := d.ensureRestored(ctx, clientOptions...); err != nil {
+		return nil, fmt.Errorf("unable to restore object: %w", err)
+	}
+
 	if d.in.PartNumber > 0 {
 		return d.singleDownload(ctx, clientOptions...)
 	}
@@ -577,7 +643,7 @@ func (d *downloader) download(ctx context.Context) (*DownloadObjectOutput, error
 		if d.in.Range != "" {
 			return d.singleDownload(ctx, clientOptions...)
 		}
-		output = d.getChunk(ctx, 1, "", clientOptions...)
+		output = d.getChunk(ctx, 1, "", 0, clientOptions...)
 		if d.getErr() != nil {
 			return output, d.err
 		}
@@ -595,7 +661,7 @@ func (d *downloader) download(ctx context.Context) (*DownloadObjectOutput, error
 					break
 				}
 
-				ch <- dlChunk{w: d.in.WriterAt, start: d.pos - d.offset, part: i}
+				ch <- dlChunk{w: d.in.WriterAt, start: d.pos - d.offset, part: i, index: int64(i - 1)}
 				d.pos += partSize
 			}
 
@@ -608,24 +674,78 @@ func (d *downloader) download(ctx context.Context) (*DownloadObjectOutput, error
 			d.offset = d.pos
 		}
 
-		d.getChunk(ctx, 0, d.byteRange(), clientOptions...)
+		matched, err := d.loadCheckpoint(ctx, clientOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resume from checkpoint: %w", err)
+		}
+		startIndex := int64(0)
+		if !matched {
+			start := d.pos
+			// index 0 is this part's number for checksum accumulation and
+			// progress reporting, not its checkpoint key (checkpoint
+			// progress is tracked by absolute byte offset instead); the
+			// "Assign work" loop below picks up numbering at 1 so it
+			// doesn't collide with the part just fetched here.
+			d.getChunk(ctx, 0, d.byteRange(), 0, clientOptions...)
+			startIndex = 1
+			if err := d.markPartComplete(ctx, start, d.pos); err != nil {
+				return nil, fmt.Errorf("unable to save checkpoint: %w", err)
+			}
+		}
 		total := d.totalBytes
 
-		ch := make(chan dlChunk, d.options.Concurrency)
-		for i := 0; i < d.options.Concurrency; i++ {
+		maxWorkers := d.options.Concurrency
+		if d.options.AdaptiveConcurrency {
+			minWorkers := d.options.MinConcurrency
+			if minWorkers <= 0 {
+				minWorkers = 1
+			}
+			maxWorkers = d.options.MaxConcurrency
+			if maxWorkers <= 0 {
+				maxWorkers = d.options.Concurrency
+			}
+			maxPartSize := d.options.MaxPartSizeBytes
+			if maxPartSize <= 0 {
+				maxPartSize = d.options.PartSizeBytes
+			}
+			d.adaptive = newAdaptiveController(minWorkers, maxWorkers, d.options.PartSizeBytes, maxPartSize)
+		}
+
+		scheduler := d.options.RangeScheduler
+		if scheduler == nil {
+			scheduler = newSequentialScheduler(d.partSizeBytes)
+		}
+		d.scheduler = scheduler
+
+		ch := make(chan dlChunk, maxWorkers)
+		for i := 0; i < maxWorkers; i++ {
 			d.wg.Add(1)
 			go d.downloadPart(ctx, ch, clientOptions...)
 		}
 
 		// Assign work
-		for d.getErr() == nil {
+		for partIndex := startIndex; d.getErr() == nil; partIndex++ {
 			if d.pos >= total {
 				break // We're finished queuing chunks
 			}
 
+			if end, ok := d.checkpointState.CompletedParts[d.pos]; ok {
+				d.pos = end
+				continue
+			}
+
+			chunk, nextPos, ok := scheduler.Next(total, d.pos)
+			if !ok {
+				break
+			}
+			chunk.w = d.in.WriterAt
+			chunk.start = d.pos - d.offset
+			chunk.absEnd = nextPos
+			chunk.index = partIndex
+
 			// Queue the next range of bytes to read.
-			ch <- dlChunk{w: d.in.WriterAt, start: d.pos - d.offset, withRange: d.byteRange()}
-			d.pos += d.options.PartSizeBytes
+			ch <- chunk
+			d.pos = nextPos
 		}
 
 		// Wait for completion
@@ -637,11 +757,119 @@ func (d *downloader) download(ctx context.Context) (*DownloadObjectOutput, error
 		return nil, d.err
 	}
 
+	if d.in.Checkpoint != nil {
+		if err := d.in.Checkpoint.Delete(ctx); err != nil {
+			return nil, fmt.Errorf("unable to delete checkpoint: %w", err)
+		}
+	}
+
+	if !d.options.DisableChecksumValidation && !d.checksumAcc.empty() {
+		if err := d.verifyAndSetChecksum(); err != nil {
+			return nil, err
+		}
+	}
+
 	d.out.ContentLength = d.written
 	d.out.ContentRange = fmt.Sprintf("bytes=%d-%d", d.offset, d.totalBytes-1)
+
+	d.emitProgress(ProgressEvent{
+		Type:     DownloadCompleted,
+		Bytes:    d.written,
+		Duration: time.Since(downloadStart),
+	})
+
 	return d.out, nil
 }
 
+// loadCheckpoint attempts to resume a prior download using d.in.Checkpoint.
+// It returns matched == true only when an existing, still-valid checkpoint
+// was found, in which case d.totalBytes, d.etag and d.checkpointState are
+// populated from it; the caller is responsible for issuing a fresh GetObject
+// (and saving a fresh checkpoint) whenever matched is false, whether because
+// there was no Checkpoint, no prior state, or a stale one.
+func (d *downloader) loadCheckpoint(ctx context.Context, clientOptions ...func(*s3.Options)) (matched bool, err error) {
+	if d.in.Checkpoint == nil {
+		return false, nil
+	}
+
+	state, ok, err := d.in.Checkpoint.Load(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, nil
+	}
+
+	head, err := d.options.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(d.in.Bucket),
+		Key:       aws.String(d.in.Key),
+		VersionId: nzstring(d.in.VersionID),
+	}, clientOptions...)
+	if err != nil {
+		return false, err
+	}
+
+	if !state.matches(aws.ToString(head.ETag), aws.ToString(head.VersionId), aws.ToInt64(head.ContentLength)) {
+		// Object changed since the checkpoint was written; discard it and
+		// restart the download from scratch.
+		return false, nil
+	}
+
+	if state.CompletedParts == nil {
+		state.CompletedParts = map[int64]int64{}
+	}
+	d.checkpointState = state
+	d.etag = state.ETag
+	d.totalBytes = state.TotalBytes
+	d.out = &DownloadObjectOutput{ETag: state.ETag, VersionID: state.VersionID}
+	return true, nil
+}
+
+// saveCheckpoint persists the downloader's current checkpoint state. It is a
+// no-op when no Checkpoint was configured.
+func (d *downloader) saveCheckpoint(ctx context.Context) error {
+	if d.in.Checkpoint == nil {
+		return nil
+	}
+
+	d.m.Lock()
+	if d.checkpointState.CompletedParts == nil {
+		d.checkpointState.CompletedParts = map[int64]int64{}
+	}
+	d.checkpointState.ETag = d.etag
+	d.checkpointState.VersionID = d.in.VersionID
+	d.checkpointState.TotalBytes = d.totalBytes
+	d.checkpointState.PartSize = d.partSizeBytes()
+	state := d.checkpointState
+	d.m.Unlock()
+
+	return d.in.Checkpoint.Save(ctx, state)
+}
+
+// recordCompletedRange marks the absolute byte range [start, end) as
+// completed in the in-memory checkpoint state. It does not persist the
+// state; callers do that via saveCheckpoint or markPartComplete.
+func (d *downloader) recordCompletedRange(start, end int64) {
+	d.m.Lock()
+	if d.checkpointState.CompletedParts == nil {
+		d.checkpointState.CompletedParts = map[int64]int64{}
+	}
+	d.checkpointState.CompletedParts[start] = end
+	d.m.Unlock()
+}
+
+// markPartComplete records that the absolute byte range [start, end) has
+// been fully written and persists the updated checkpoint state, if a
+// Checkpoint is configured.
+func (d *downloader) markPartComplete(ctx context.Context, start, end int64) error {
+	if d.in.Checkpoint == nil {
+		return nil
+	}
+
+	d.recordCompletedRange(start, end)
+	return d.saveCheckpoint(ctx)
+}
+
 func (d *downloader) init() error {
 	if d.options.PartSizeBytes < minPartSizeBytes {
 		return fmt.Errorf("part size must be at least %d bytes", minPartSizeBytes)
@@ -657,7 +885,7 @@ func (d *downloader) init() error {
 }
 
 func (d *downloader) singleDownload(ctx context.Context, clientOptions ...func(*s3.Options)) (*DownloadObjectOutput, error) {
-	chunk := dlChunk{w: d.in.WriterAt}
+	chunk := dlChunk{w: d.in.WriterAt, index: -1}
 	output, err := d.downloadChunk(ctx, chunk, clientOptions...)
 	if err != nil {
 		return nil, err
@@ -676,19 +904,53 @@ func (d *downloader) downloadPart(ctx context.Context, ch chan dlChunk, clientOp
 		if d.getErr() != nil {
 			continue
 		}
+		if d.adaptive != nil {
+			if err := d.adaptive.acquire(ctx); err != nil {
+				d.setErr(err)
+				continue
+			}
+		}
+		start := time.Now()
 		out, err := d.downloadChunk(ctx, chunk, clientOptions...)
+		if d.scheduler != nil {
+			d.scheduler.OnComplete(chunk, err)
+			for err != nil {
+				retry, ok := d.scheduler.Retry(chunk)
+				if !ok {
+					break
+				}
+				chunk = retry
+				out, err = d.downloadChunk(ctx, chunk, clientOptions...)
+				d.scheduler.OnComplete(chunk, err)
+			}
+		}
+		if d.adaptive != nil {
+			if err == nil {
+				d.adaptive.report(chunkStat{bytes: out.ContentLength, duration: time.Since(start)})
+			}
+			d.adaptive.release()
+		}
 		if err != nil {
 			d.setErr(err)
 		} else {
 			d.setOutput(out)
+			if chunk.absEnd > 0 {
+				if err := d.markPartComplete(ctx, chunk.start+d.offset, chunk.absEnd); err != nil {
+					d.setErr(err)
+				}
+			}
 		}
 	}
 }
 
-// getChunk grabs a chunk of data from the body.
+// getChunk grabs a chunk of data from the body. index is recorded on the
+// chunk like any other (so its checksum, if any, is accumulated and its
+// progress events numbered); callers that don't want it counted - namely
+// singleDownload, which covers the whole object in one part - should keep
+// using -1 directly instead of calling getChunk.
 // Not thread safe. Should only used when grabbing data on a single thread.
-func (d *downloader) getChunk(ctx context.Context, part int32, rng string, clientOptions ...func(*s3.Options)) *DownloadObjectOutput {
-	chunk := dlChunk{w: d.in.WriterAt, start: d.pos - d.offset, part: part, withRange: rng}
+func (d *downloader) getChunk(ctx context.Context, part int32, rng string, index int64, clientOptions ...func(*s3.Options)) *DownloadObjectOutput {
+	chunk := dlChunk{w: d.in.WriterAt, start: d.pos - d.offset, part: part, withRange: rng, index: index}
 
 	output, err := d.downloadChunk(ctx, chunk, clientOptions...)
 	if err != nil {
@@ -714,10 +976,36 @@ func (d *downloader) downloadChunk(ctx context.Context, chunk dlChunk, clientOpt
 		params.IfMatch = aws.String(d.etag)
 	}
 
+	rangeStart, rangeEnd, hasRange := parseRangeHeader(chunk.withRange)
+
+	partStart := time.Now()
+	d.emitProgress(ProgressEvent{Type: PartStarted, Part: chunk.index})
+
+	lastProgressAt := partStart
+	var pendingBytes int64
+	chunk.onBytes = func(n int) {
+		pendingBytes += int64(n)
+		if time.Since(lastProgressAt) < progressBytesInterval {
+			return
+		}
+		lastProgressAt = time.Now()
+		d.emitProgress(ProgressEvent{Type: PartBytes, Part: chunk.index, Bytes: pendingBytes})
+		pendingBytes = 0
+	}
+
 	var out *s3.GetObjectOutput
 	var n int64
 	var err error
+	attempts := 0
 	for retry := 0; retry < d.options.PartBodyMaxRetries; retry++ {
+		if hasRange && chunk.cur > 0 {
+			// A previous attempt already wrote chunk.cur bytes of this
+			// range; only fetch what's left instead of re-downloading the
+			// whole part.
+			params.Range = aws.String(fmt.Sprintf("bytes=%d-%d", rangeStart+chunk.cur, rangeEnd))
+		}
+
+		attempts++
 		out, n, err = d.tryDownloadChunk(ctx, params, &chunk, clientOptions...)
 		if err == nil {
 			break
@@ -726,18 +1014,51 @@ func (d *downloader) downloadChunk(ctx context.Context, chunk dlChunk, clientOpt
 		// If err is errReadingBody this indicates that an error
 		// occurred while copying the http response body.
 		// If this occurs we unwrap the err to set the underlying error
-		// and attempt any remaining retries.
+		// and attempt any remaining retries, resuming from the byte
+		// offset already written instead of restarting the whole part.
 		if bodyErr, ok := err.(*errReadingBody); ok {
 			err = bodyErr
+			if !hasRange {
+				// No absolute range to resume from (single-part or
+				// PartNumber-based download); fall back to restarting the
+				// whole chunk, as before.
+				chunk.cur = 0
+			}
+			d.stats.recordRetry()
+			d.emitProgress(ProgressEvent{Type: PartRetried, Part: chunk.index, Attempts: attempts})
+		} else if d.in.RestoreOnDemand != nil && isInvalidObjectState(err) {
+			// The object was archived after our preflight HeadObject (or
+			// RestoreOnDemand was added without one ever running); restore
+			// it on demand and retry this chunk once restored.
+			if restoreErr := d.ensureRestored(ctx, clientOptions...); restoreErr != nil {
+				return nil, restoreErr
+			}
+			chunk.cur = 0
+			if hasRange {
+				params.Range = aws.String(chunk.withRange)
+			}
+			d.stats.recordRetry()
+			d.emitProgress(ProgressEvent{Type: PartRetried, Part: chunk.index, Attempts: attempts})
 		} else {
+			d.emitProgress(ProgressEvent{Type: PartCompleted, Part: chunk.index, Duration: time.Since(partStart), Attempts: attempts, Err: err})
 			return nil, err
 		}
-
-		chunk.cur = 0
 	}
 
+	// chunk.cur accumulates bytes written across sub-range retries, so it -
+	// not n, which only reflects the final attempt - is this chunk's true
+	// total.
+	if hasRange {
+		n = chunk.cur
+	}
 	d.incrWritten(n)
 
+	partDuration := time.Since(partStart)
+	if err == nil {
+		d.stats.recordPartLatency(partDuration)
+	}
+	d.emitProgress(ProgressEvent{Type: PartCompleted, Part: chunk.index, Bytes: n, Duration: partDuration, Attempts: attempts, Err: err})
+
 	var output *DownloadObjectOutput
 	if out != nil {
 		output = &DownloadObjectOutput{}
@@ -745,11 +1066,19 @@ func (d *downloader) downloadChunk(ctx context.Context, chunk dlChunk, clientOpt
 		d.etagOnce.Do(func() {
 			d.etag = aws.ToString(out.ETag)
 		})
+		if chunk.index >= 0 {
+			if pc, ok := extractPartChecksum(output, n); ok {
+				d.checksumAcc.add(chunk.index, pc)
+			}
+		}
 	}
 	return output, err
 }
 
 func (d *downloader) tryDownloadChunk(ctx context.Context, params *s3.GetObjectInput, chunk *dlChunk, clientOptions ...func(*s3.Options)) (*s3.GetObjectOutput, int64, error) {
+	if chunk.clientOptions != nil {
+		clientOptions = append(append([]func(*s3.Options){}, clientOptions...), chunk.clientOptions)
+	}
 	out, err := d.options.S3.GetObject(ctx, params, clientOptions...)
 	if err != nil {
 		return nil, 0, err
@@ -759,11 +1088,14 @@ func (d *downloader) tryDownloadChunk(ctx context.Context, params *s3.GetObjectI
 		d.setTotalBytes(out)
 	}) // Set total in first GET
 
+	d.stats.addBytesInFlight(aws.ToInt64(out.ContentLength))
+	defer d.stats.addBytesInFlight(-aws.ToInt64(out.ContentLength))
+
 	var n int64
 	defer out.Body.Close()
-	n, err = io.Copy(chunk, out.Body)
+	n, err = io.Copy(chunk, d.wrapProgress(ctx, out.Body))
 	if err != nil {
-		return nil, 0, &errReadingBody{err: err}
+		return nil, 0, &errReadingBody{err: err, bytesWritten: n}
 	}
 
 	return out, n, nil
@@ -840,13 +1172,49 @@ func (d *downloader) getDownloadRange() (int64, int64) {
 	return start, end + 1
 }
 
+// parseRangeHeader parses a "bytes=start-end" Range header value as used
+// internally to request chunk ranges. ok is false for values it doesn't
+// recognize, such as an empty string (no range requested).
+func parseRangeHeader(rng string) (start, end int64, ok bool) {
+	if rng == "" {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(rng, "bytes="), "-")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	end, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
+
 // byteRange returns a HTTP Byte-Range header value that should be used by the
 // client to request a chunk range.
 func (d *downloader) byteRange() string {
+	partSize := d.partSizeBytes()
 	if d.totalBytes >= 0 {
-		return fmt.Sprintf("bytes=%d-%d", d.pos, int64(math.Min(float64(d.totalBytes-1), float64(d.pos+d.options.PartSizeBytes-1))))
+		return fmt.Sprintf("bytes=%d-%d", d.pos, int64(math.Min(float64(d.totalBytes-1), float64(d.pos+partSize-1))))
+	}
+	return fmt.Sprintf("bytes=%d-%d", d.pos, d.pos+partSize-1)
+}
+
+// partSizeBytes returns the part size to use for the next queued chunk: the
+// adaptiveController's current target when AdaptiveConcurrency is enabled,
+// or the static Options.PartSizeBytes otherwise.
+func (d *downloader) partSizeBytes() int64 {
+	if d.adaptive != nil {
+		return d.adaptive.currentPartSize()
 	}
-	return fmt.Sprintf("bytes=%d-%d", d.pos, d.pos+d.options.PartSizeBytes-1)
+	return d.options.PartSizeBytes
 }
 
 func (d *downloader) getErr() error {
@@ -858,9 +1226,15 @@ func (d *downloader) getErr() error {
 
 func (d *downloader) setErr(e error) {
 	d.m.Lock()
-	defer d.m.Unlock()
-
+	cancel := d.cancel
 	d.err = e
+	d.m.Unlock()
+
+	// Abort every other in-flight part now that the download has failed,
+	// rather than waiting for them to finish on their own.
+	if cancel != nil {
+		cancel()
+	}
 }
 
 type dlChunk struct {
@@ -869,13 +1243,39 @@ type dlChunk struct {
 	start int64
 	cur   int64
 
+	// absEnd is this chunk's absolute (offset-from-object-start, not
+	// WriterAt-relative like start) exclusive end-of-range, used together
+	// with start+d.offset to record checkpoint progress keyed by absolute
+	// byte offset. It is unused (0) for the multipart-parts and
+	// single-download paths, which never consult a Checkpoint.
+	absEnd int64
+
 	part      int32
 	withRange string
+
+	// index is this chunk's zero-based position in the sequential,
+	// range-based download path. It is used for checksum accumulation and
+	// progress-event numbering, and is unused (-1) for the
+	// single-download path.
+	index int64
+
+	// clientOptions, when set by a RangeScheduler, rewrites the s3.Options
+	// used for this chunk's GetObject call (typically BaseEndpoint), so
+	// different ranges of the same object can be routed to different
+	// cache/proxy endpoints.
+	clientOptions func(*s3.Options)
+
+	// onBytes, when set, is invoked after each successful WriteAt with the
+	// number of bytes just written, driving PartBytes progress events.
+	onBytes func(n int)
 }
 
 func (c *dlChunk) Write(p []byte) (int, error) {
 	n, err := c.w.WriteAt(p, c.start+c.cur)
 	c.cur += int64(n)
+	if n > 0 && c.onBytes != nil {
+		c.onBytes(n)
+	}
 
 	return n, err
 }
@@ -0,0 +1,242 @@
+package transfermanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DownloadObjectStream downloads an object using the same parallel, ranged
+// GetObject engine as DownloadObject, but returns the object as a sequential
+// io.ReadCloser instead of requiring a pre-allocated, seekable io.WriterAt.
+// This is useful for piping a download directly into a decoder (video
+// transcode, tar extract, streaming JSON parser) without staging the whole
+// object on disk first.
+//
+// Parts are fetched concurrently (up to Options.Concurrency at a time), but
+// are only released to the returned reader in offset order; a bounded ring
+// of in-memory part buffers (sized Concurrency x PartSizeBytes) provides
+// backpressure, so a worker that finishes a part far ahead of the reader
+// blocks until the reader catches up rather than buffering the whole object.
+//
+// Closing the returned reader before it reaches EOF cancels all in-flight
+// range GETs and releases any buffered parts.
+func (c *Client) DownloadObjectStream(ctx context.Context, input *DownloadObjectInput, opts ...func(*Options)) (*DownloadObjectOutput, io.ReadCloser, error) {
+	options := c.options.Copy()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.PartSizeBytes < minPartSizeBytes {
+		return nil, nil, fmt.Errorf("part size must be at least %d bytes", minPartSizeBytes)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	clientOptions := []func(*s3.Options){
+		func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions,
+				middleware.AddSDKAgentKey(middleware.FeatureMetadata, userAgentKey),
+				addFeatureUserAgent,
+			)
+		},
+	}
+
+	head, err := options.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(input.Bucket),
+		Key:       aws.String(input.Key),
+		VersionId: nzstring(input.VersionID),
+	}, clientOptions...)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	output := &DownloadObjectOutput{}
+	output.mapFromGetObjectOutput(&s3.GetObjectOutput{
+		AcceptRanges:         head.AcceptRanges,
+		ETag:                 head.ETag,
+		LastModified:         head.LastModified,
+		ContentLength:        head.ContentLength,
+		ContentType:          head.ContentType,
+		VersionId:            head.VersionId,
+		ServerSideEncryption: head.ServerSideEncryption,
+		StorageClass:         head.StorageClass,
+	}, "")
+
+	totalBytes := aws.ToInt64(head.ContentLength)
+	pr, pw := io.Pipe()
+
+	s := &streamDownloader{
+		ctx:        ctx,
+		cancel:     cancel,
+		options:    options,
+		in:         input,
+		totalBytes: totalBytes,
+		etag:       aws.ToString(head.ETag),
+		pw:         pw,
+		ready:      make(chan *bufferedPart),
+		sem:        make(chan struct{}, maxInt(options.Concurrency, 1)),
+		window:     newPartWindow(int64(maxInt(options.Concurrency, 1))),
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.window.cancel()
+	}()
+	go s.run(clientOptions...)
+	go s.assemble()
+
+	return output, &streamReadCloser{r: pr, cancel: cancel}, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// bufferedPart is one range's fully-downloaded bytes, tagged with its
+// position in the object so the assembler can release parts in order.
+type bufferedPart struct {
+	index int64
+	data  []byte
+	err   error
+}
+
+// streamDownloader fetches an object's ranges concurrently and hands
+// completed parts to an assembler goroutine over the ready channel, in
+// whatever order they finish.
+type streamDownloader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	options Options
+	in      *DownloadObjectInput
+
+	totalBytes int64
+	etag       string
+
+	pw     *io.PipeWriter
+	ready  chan *bufferedPart
+	sem    chan struct{}
+	window *partWindow
+
+	wg sync.WaitGroup
+}
+
+func (s *streamDownloader) run(clientOptions ...func(*s3.Options)) {
+	partSize := s.options.PartSizeBytes
+
+	var index int64
+	for pos := int64(0); pos < s.totalBytes; pos += partSize {
+		end := pos + partSize - 1
+		if end > s.totalBytes-1 {
+			end = s.totalBytes - 1
+		}
+
+		select {
+		case s.sem <- struct{}{}:
+		case <-s.ctx.Done():
+			s.wg.Wait()
+			close(s.ready)
+			return
+		}
+
+		s.wg.Add(1)
+		go s.downloadPart(index, pos, end, clientOptions...)
+		index++
+	}
+
+	s.wg.Wait()
+	close(s.ready)
+}
+
+func (s *streamDownloader) downloadPart(index, start, end int64, clientOptions ...func(*s3.Options)) {
+	defer s.wg.Done()
+	defer func() { <-s.sem }()
+
+	if !s.window.waitForSlot(s.ctx, index) {
+		return
+	}
+
+	params := s.in.mapGetObjectInput(!s.options.DisableChecksumValidation)
+	params.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, end))
+	if params.VersionId == nil && s.etag != "" {
+		params.IfMatch = aws.String(s.etag)
+	}
+
+	part := &bufferedPart{index: index}
+	out, err := s.options.S3.GetObject(s.ctx, params, clientOptions...)
+	if err != nil {
+		part.err = err
+	} else {
+		defer out.Body.Close()
+		data, rerr := io.ReadAll(out.Body)
+		part.data = data
+		part.err = rerr
+	}
+
+	select {
+	case s.ready <- part:
+	case <-s.ctx.Done():
+	}
+}
+
+// assemble drains parts from ready and writes them to pw strictly in
+// offset order, buffering any that arrive ahead of next in pending. It
+// advances window as each part is written, which is what keeps pending
+// from growing past Concurrency entries even when the earliest part is
+// slow to arrive: downloadPart won't even start a part that far ahead.
+func (s *streamDownloader) assemble() {
+	pending := map[int64]*bufferedPart{}
+	var next int64
+	var finalErr error
+
+	for part := range s.ready {
+		if part.err != nil && finalErr == nil {
+			finalErr = part.err
+		}
+		pending[part.index] = part
+
+		for {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			s.window.advance(p.index)
+
+			if finalErr == nil && len(p.data) > 0 {
+				if _, err := s.pw.Write(p.data); err != nil {
+					finalErr = err
+				}
+			}
+		}
+	}
+
+	s.pw.CloseWithError(finalErr)
+}
+
+// streamReadCloser cancels the underlying download when closed before EOF,
+// on top of closing the pipe reader itself.
+type streamReadCloser struct {
+	r      *io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (s *streamReadCloser) Read(p []byte) (int, error) {
+	return s.r.Read(p)
+}
+
+func (s *streamReadCloser) Close() error {
+	s.cancel()
+	return s.r.Close()
+}
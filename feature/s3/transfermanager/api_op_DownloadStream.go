@@ -0,0 +1,264 @@
+package transfermanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// bufferedChunk is one range's downloaded bytes together with the part
+// index it occupies in the object, and whether the download filling it
+// failed.
+type bufferedChunk struct {
+	index int64
+	data  []byte
+	err   error
+}
+
+// chanMultiReader reads a sequence of bufferedChunks off a channel and
+// exposes them as a single contiguous io.Reader: it blocks on chunk N until
+// that chunk arrives, streams its bytes, then moves on to N+1. Chunks may
+// arrive out of order and are reordered using a pending buffer - window
+// keeps that buffer bounded by holding back the workers that would grow it
+// past maxBuffered, rather than relying on the channel alone.
+type chanMultiReader struct {
+	chunks <-chan bufferedChunk
+	window *partWindow
+
+	pending map[int64]bufferedChunk
+	next    int64
+	cur     []byte
+	err     error
+}
+
+func newChanMultiReader(chunks <-chan bufferedChunk, window *partWindow) *chanMultiReader {
+	return &chanMultiReader{chunks: chunks, window: window, pending: map[int64]bufferedChunk{}}
+}
+
+func (r *chanMultiReader) Read(p []byte) (int, error) {
+	for len(r.cur) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if c, ok := r.pending[r.next]; ok {
+			delete(r.pending, r.next)
+			r.next++
+			r.window.advance(c.index)
+			if c.err != nil {
+				r.err = c.err
+				continue
+			}
+			r.cur = c.data
+			continue
+		}
+
+		c, ok := <-r.chunks
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+		r.pending[c.index] = c
+	}
+
+	n := copy(p, r.cur)
+	r.cur = r.cur[n:]
+	return n, nil
+}
+
+// partWindow bounds how far ahead of the reader's current position a
+// worker may finish downloading a part: a worker for index must wait until
+// the reader has advanced to within maxBuffered of it, so completed-but-
+// unread parts can't pile up in chanMultiReader's pending map without limit
+// regardless of how far out of order they complete.
+type partWindow struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	next int64
+	max  int64
+}
+
+func newPartWindow(max int64) *partWindow {
+	w := &partWindow{max: max}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+// waitForSlot blocks until index is within max of the reader's current
+// position, or ctx is done, reporting which happened.
+func (w *partWindow) waitForSlot(ctx context.Context, index int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for index-w.next >= w.max {
+		if ctx.Err() != nil {
+			return false
+		}
+		w.cond.Wait()
+	}
+	return ctx.Err() == nil
+}
+
+// advance records that the reader has consumed index, waking any worker
+// waiting for room to open up ahead of it.
+func (w *partWindow) advance(index int64) {
+	w.mu.Lock()
+	if index+1 > w.next {
+		w.next = index + 1
+	}
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// cancel wakes every waiter so a canceled download's workers can notice ctx
+// is done instead of blocking on a slot that will never open.
+func (w *partWindow) cancel() {
+	w.cond.Broadcast()
+}
+
+// DownloadStream downloads an object using the parallel, ranged GetObject
+// engine, applying backpressure on the workers via MaxBufferedParts instead
+// of requiring a pre-allocated io.WriterAt: a worker does not start part N
+// until the returned reader has come within MaxBufferedParts of it, so at
+// most MaxBufferedParts completed-but-unread parts are ever buffered.
+func (c *Client) DownloadStream(ctx context.Context, input *DownloadObjectInput, opts ...func(*Options)) (io.ReadCloser, *DownloadObjectOutput, error) {
+	options := c.options.Copy()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.PartSizeBytes < minPartSizeBytes {
+		return nil, nil, fmt.Errorf("part size must be at least %d bytes", minPartSizeBytes)
+	}
+
+	maxBuffered := options.MaxBufferedParts
+	if maxBuffered <= 0 {
+		maxBuffered = options.Concurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	clientOptions := []func(*s3.Options){
+		func(o *s3.Options) {
+			o.APIOptions = append(o.APIOptions,
+				middleware.AddSDKAgentKey(middleware.FeatureMetadata, userAgentKey),
+				addFeatureUserAgent,
+			)
+		},
+	}
+
+	head, err := options.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(input.Bucket),
+		Key:       aws.String(input.Key),
+		VersionId: nzstring(input.VersionID),
+	}, clientOptions...)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	output := &DownloadObjectOutput{}
+	output.mapFromGetObjectOutput(&s3.GetObjectOutput{
+		AcceptRanges:         head.AcceptRanges,
+		ETag:                 head.ETag,
+		LastModified:         head.LastModified,
+		ContentLength:        head.ContentLength,
+		ContentType:          head.ContentType,
+		VersionId:            head.VersionId,
+		ServerSideEncryption: head.ServerSideEncryption,
+		StorageClass:         head.StorageClass,
+	}, "")
+
+	chunks := make(chan bufferedChunk, maxBuffered)
+	totalBytes := aws.ToInt64(head.ContentLength)
+	etag := aws.ToString(head.ETag)
+	window := newPartWindow(int64(maxBuffered))
+
+	go runChunkedDownload(ctx, options, input, totalBytes, etag, chunks, window, clientOptions...)
+
+	return &chanStreamReadCloser{r: newChanMultiReader(chunks, window), cancel: cancel}, output, nil
+}
+
+// runChunkedDownload dispatches one goroutine per range, bounded by
+// Concurrency in flight at a time, and sends each result to chunks as it
+// completes. It closes chunks once every range has been dispatched and has
+// reported in.
+func runChunkedDownload(ctx context.Context, options Options, input *DownloadObjectInput, totalBytes int64, etag string, chunks chan<- bufferedChunk, window *partWindow, clientOptions ...func(*s3.Options)) {
+	defer close(chunks)
+
+	go func() {
+		<-ctx.Done()
+		window.cancel()
+	}()
+
+	sem := make(chan struct{}, maxInt(options.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	var index int64
+	for pos := int64(0); pos < totalBytes; pos += options.PartSizeBytes {
+		end := pos + options.PartSizeBytes - 1
+		if end > totalBytes-1 {
+			end = totalBytes - 1
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(index, start, end int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if !window.waitForSlot(ctx, index) {
+				return
+			}
+
+			params := input.mapGetObjectInput(!options.DisableChecksumValidation)
+			params.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, end))
+			if params.VersionId == nil && etag != "" {
+				params.IfMatch = aws.String(etag)
+			}
+
+			chunk := bufferedChunk{index: index}
+			out, err := options.S3.GetObject(ctx, params, clientOptions...)
+			if err != nil {
+				chunk.err = err
+			} else {
+				defer out.Body.Close()
+				chunk.data, chunk.err = io.ReadAll(out.Body)
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+			}
+		}(index, pos, end)
+		index++
+	}
+
+	wg.Wait()
+}
+
+// chanStreamReadCloser cancels the underlying download when closed before
+// EOF, on top of exposing the reordering reader itself.
+type chanStreamReadCloser struct {
+	r      *chanMultiReader
+	cancel context.CancelFunc
+}
+
+func (c *chanStreamReadCloser) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *chanStreamReadCloser) Close() error {
+	c.cancel()
+	return nil
+}
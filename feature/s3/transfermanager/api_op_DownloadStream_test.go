@@ -0,0 +1,110 @@
+package transfermanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestChanMultiReaderReordersChunks(t *testing.T) {
+	chunks := make(chan bufferedChunk, 3)
+	chunks <- bufferedChunk{index: 1, data: []byte("world")}
+	chunks <- bufferedChunk{index: 0, data: []byte("hello ")}
+	chunks <- bufferedChunk{index: 2, data: []byte("!")}
+	close(chunks)
+
+	r := newChanMultiReader(chunks, newPartWindow(10))
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(got) != "hello world!" {
+		t.Errorf("ReadAll() = %q, want %q", got, "hello world!")
+	}
+}
+
+func TestChanMultiReaderPropagatesChunkError(t *testing.T) {
+	wantErr := errors.New("download failed")
+	chunks := make(chan bufferedChunk, 1)
+	chunks <- bufferedChunk{index: 0, err: wantErr}
+	close(chunks)
+
+	r := newChanMultiReader(chunks, newPartWindow(10))
+	_, err := io.ReadAll(r)
+	if !errors.Is(err, wantErr) {
+		t.Errorf("ReadAll() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestChanMultiReaderAdvancesWindow(t *testing.T) {
+	chunks := make(chan bufferedChunk, 2)
+	chunks <- bufferedChunk{index: 0, data: []byte("a")}
+	chunks <- bufferedChunk{index: 1, data: []byte("b")}
+	close(chunks)
+
+	window := newPartWindow(1)
+	r := newChanMultiReader(chunks, window)
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	if !window.waitForSlot(context.Background(), 2) {
+		t.Error("waitForSlot(2) = false after the reader consumed chunks 0 and 1, want true")
+	}
+}
+
+func TestPartWindowWaitsUntilAdvanced(t *testing.T) {
+	w := newPartWindow(2)
+
+	unblocked := make(chan bool, 1)
+	go func() {
+		unblocked <- w.waitForSlot(context.Background(), 2)
+	}()
+
+	select {
+	case <-unblocked:
+		t.Fatal("waitForSlot(2) returned before the window advanced, want it to block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.advance(0)
+
+	select {
+	case ok := <-unblocked:
+		if !ok {
+			t.Error("waitForSlot(2) = false after advance(0), want true")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForSlot(2) still blocked after advance(0) opened its slot")
+	}
+}
+
+func TestPartWindowCancelUnblocksWaiters(t *testing.T) {
+	w := newPartWindow(1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	unblocked := make(chan bool, 1)
+	go func() {
+		unblocked <- w.waitForSlot(ctx, 5)
+	}()
+
+	select {
+	case <-unblocked:
+		t.Fatal("waitForSlot(5) returned before cancellation, want it to block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	w.cancel()
+
+	select {
+	case ok := <-unblocked:
+		if ok {
+			t.Error("waitForSlot(5) = true after ctx cancellation, want false")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForSlot(5) still blocked after cancel()")
+	}
+}
@@ -0,0 +1,109 @@
+package transfermanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CheckpointState captures enough information about an in-progress
+// DownloadObject call to resume it across process restarts. PartSize and
+// CompletedParts are only meaningful for the chunked, range-based download
+// path; single-part and non-resumable downloads never produce or consume a
+// CheckpointState.
+type CheckpointState struct {
+	// ETag of the object being downloaded, captured from the first response.
+	ETag string
+
+	// VersionID of the object being downloaded, if any.
+	VersionID string
+
+	// TotalBytes is the full size of the object.
+	TotalBytes int64
+
+	// PartSize is the byte-range size that was in effect when the
+	// checkpoint was last saved. It's recorded for diagnostic purposes
+	// only - CompletedParts is keyed by absolute byte offset rather than
+	// part index, so resuming with a different PartSize (including one
+	// adaptive part sizing grew mid-download) can't misalign part
+	// boundaries and doesn't need to match.
+	PartSize int64
+
+	// CompletedParts records which byte ranges have already been written
+	// to the destination WriterAt, keyed by each range's absolute start
+	// offset and valued by its (exclusive) end offset.
+	CompletedParts map[int64]int64
+}
+
+// matches reports whether this checkpoint state is still valid for an object
+// whose current ETag/VersionID/size are as given. A mismatch means the
+// object changed since the checkpoint was saved and any partial progress
+// must be discarded.
+func (s *CheckpointState) matches(etag, versionID string, totalBytes int64) bool {
+	return s != nil &&
+		s.ETag == etag &&
+		s.VersionID == versionID &&
+		s.TotalBytes == totalBytes
+}
+
+// Checkpoint persists download progress so a DownloadObject call can resume
+// after an interruption instead of restarting from scratch.
+type Checkpoint interface {
+	// Load returns the previously saved state, or ok == false if none exists.
+	Load(ctx context.Context) (state CheckpointState, ok bool, err error)
+
+	// Save persists state, overwriting any previously saved state.
+	Save(ctx context.Context, state CheckpointState) error
+
+	// Delete removes any saved state. It is called once a download completes
+	// successfully, and must not return an error when no state exists.
+	Delete(ctx context.Context) error
+}
+
+// FileCheckpoint is a Checkpoint implementation backed by a single JSON file
+// on the local filesystem.
+type FileCheckpoint struct {
+	// Path is the file the checkpoint state is read from and written to.
+	Path string
+}
+
+// NewFileCheckpoint returns a FileCheckpoint that stores state at path.
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{Path: path}
+}
+
+// Load implements Checkpoint.
+func (c *FileCheckpoint) Load(ctx context.Context) (CheckpointState, bool, error) {
+	b, err := os.ReadFile(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return CheckpointState{}, false, nil
+		}
+		return CheckpointState{}, false, err
+	}
+
+	var state CheckpointState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return CheckpointState{}, false, fmt.Errorf("unmarshal checkpoint state: %w", err)
+	}
+	return state, true, nil
+}
+
+// Save implements Checkpoint.
+func (c *FileCheckpoint) Save(ctx context.Context, state CheckpointState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint state: %w", err)
+	}
+	return os.WriteFile(c.Path, b, 0644)
+}
+
+// Delete implements Checkpoint.
+func (c *FileCheckpoint) Delete(ctx context.Context) error {
+	err := os.Remove(c.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
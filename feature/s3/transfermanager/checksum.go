@@ -0,0 +1,318 @@
+package transfermanager
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"sync"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ChecksumMismatchError is returned by DownloadObject when the checksum
+// computed locally from the downloaded bytes does not match the checksum
+// S3 reported for the object.
+type ChecksumMismatchError struct {
+	Algorithm s3types.ChecksumAlgorithm
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch (%s): expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+// partChecksum is one ranged GetObject response's contribution to the
+// object's end-to-end checksum: its raw (not base64-encoded) digest, the
+// number of bytes it covered, and which algorithm produced it.
+type partChecksum struct {
+	algorithm s3types.ChecksumAlgorithm
+	raw       []byte
+	length    int64
+}
+
+// checksumAccumulator collects per-part checksums in completion order and
+// reassembles the composite (or full-object CRC32C) checksum once every part
+// has reported in.
+type checksumAccumulator struct {
+	mu    sync.Mutex
+	parts map[int64]partChecksum
+}
+
+func (c *checksumAccumulator) add(index int64, pc partChecksum) {
+	if index < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.parts == nil {
+		c.parts = map[int64]partChecksum{}
+	}
+	c.parts[index] = pc
+}
+
+func (c *checksumAccumulator) empty() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.parts) == 0
+}
+
+// ordered returns the accumulated parts sorted by part index.
+func (c *checksumAccumulator) ordered() []partChecksum {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	indexes := make([]int64, 0, len(c.parts))
+	for idx := range c.parts {
+		indexes = append(indexes, idx)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	out := make([]partChecksum, len(indexes))
+	for i, idx := range indexes {
+		out[i] = c.parts[idx]
+	}
+	return out
+}
+
+// extractPartChecksum pulls whichever checksum field is populated on out, if
+// any, decoding it from base64 into its raw digest bytes.
+func extractPartChecksum(out *DownloadObjectOutput, length int64) (partChecksum, bool) {
+	candidates := []struct {
+		alg   s3types.ChecksumAlgorithm
+		value string
+	}{
+		{s3types.ChecksumAlgorithmCrc32, out.ChecksumCRC32},
+		{s3types.ChecksumAlgorithmCrc32c, out.ChecksumCRC32C},
+		{s3types.ChecksumAlgorithmSha1, out.ChecksumSHA1},
+		{s3types.ChecksumAlgorithmSha256, out.ChecksumSHA256},
+	}
+
+	for _, c := range candidates {
+		if c.value == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(c.value)
+		if err != nil {
+			continue
+		}
+		return partChecksum{algorithm: c.alg, raw: raw, length: length}, true
+	}
+	return partChecksum{}, false
+}
+
+// verifyChecksum reassembles the accumulated per-part checksums into the
+// object's end-to-end checksum and compares it against expected (the value
+// S3 returned on the first response). It returns the locally computed,
+// base64-encoded checksum alongside any mismatch error.
+//
+// Which reassembly scheme to use is decided by expected's shape, not by
+// algorithm: a composite multipart checksum carries a "-<part count>"
+// suffix (compositeChecksum reproduces that too), while a full-object
+// checksum is the plain digest of the whole object and needs the CRC
+// combine instead - and CRC32C objects are uploaded both ways, so the
+// algorithm alone can't tell them apart.
+func verifyChecksum(acc *checksumAccumulator, expected string) (actual string, err error) {
+	parts := acc.ordered()
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	algorithm := parts[0].algorithm
+	composite := strings.Contains(expected, "-")
+	if expected == "" {
+		// Nothing to compare against; default to whichever scheme this
+		// algorithm can even produce standalone - CRC32/CRC32C support a
+		// full-object combine, SHA1/SHA256 only ever produce a composite.
+		composite = algorithm != s3types.ChecksumAlgorithmCrc32 && algorithm != s3types.ChecksumAlgorithmCrc32c
+	}
+
+	var computed string
+	if composite {
+		computed = compositeChecksum(algorithm, parts)
+	} else {
+		poly, ok := crc32PolyFor(algorithm)
+		if !ok {
+			return "", fmt.Errorf("full-object checksum verification is not supported for %s", algorithm)
+		}
+		computed = base64.StdEncoding.EncodeToString(crc32CombineAll(poly, parts))
+	}
+
+	if expected == "" {
+		return computed, nil
+	}
+	if computed != expected {
+		return computed, &ChecksumMismatchError{Algorithm: algorithm, Expected: expected, Actual: computed}
+	}
+	return computed, nil
+}
+
+// crc32PolyFor returns the CRC polynomial algorithm uses, for the
+// algorithms that support a full-object combine.
+func crc32PolyFor(algorithm s3types.ChecksumAlgorithm) (uint32, bool) {
+	switch algorithm {
+	case s3types.ChecksumAlgorithmCrc32:
+		return crc32.IEEE, true
+	case s3types.ChecksumAlgorithmCrc32c:
+		return crc32.Castagnoli, true
+	default:
+		return 0, false
+	}
+}
+
+// verifyAndSetChecksum reassembles the downloader's accumulated per-part
+// checksums, verifies them against the value S3 reported on the object, and
+// populates the matching Checksum field on d.out with the locally computed
+// value.
+func (d *downloader) verifyAndSetChecksum() error {
+	parts := d.checksumAcc.ordered()
+	if len(parts) == 0 {
+		return nil
+	}
+
+	var expected string
+	switch parts[0].algorithm {
+	case s3types.ChecksumAlgorithmCrc32:
+		expected = d.out.ChecksumCRC32
+	case s3types.ChecksumAlgorithmCrc32c:
+		expected = d.out.ChecksumCRC32C
+	case s3types.ChecksumAlgorithmSha1:
+		expected = d.out.ChecksumSHA1
+	case s3types.ChecksumAlgorithmSha256:
+		expected = d.out.ChecksumSHA256
+	}
+
+	actual, err := verifyChecksum(&d.checksumAcc, expected)
+	if err != nil {
+		return err
+	}
+
+	switch parts[0].algorithm {
+	case s3types.ChecksumAlgorithmCrc32:
+		d.out.ChecksumCRC32 = actual
+	case s3types.ChecksumAlgorithmCrc32c:
+		d.out.ChecksumCRC32C = actual
+	case s3types.ChecksumAlgorithmSha1:
+		d.out.ChecksumSHA1 = actual
+	case s3types.ChecksumAlgorithmSha256:
+		d.out.ChecksumSHA256 = actual
+	}
+	return nil
+}
+
+// compositeChecksum implements S3's "composite of checksums" scheme used by
+// legacy multipart uploads: concatenate the raw per-part digests in part
+// order, hash the concatenation with the same algorithm, and append
+// "-<number of parts>".
+func compositeChecksum(algorithm s3types.ChecksumAlgorithm, parts []partChecksum) string {
+	var concatenated []byte
+	for _, p := range parts {
+		concatenated = append(concatenated, p.raw...)
+	}
+
+	var digest []byte
+	switch algorithm {
+	case s3types.ChecksumAlgorithmCrc32:
+		sum := crc32.ChecksumIEEE(concatenated)
+		digest = []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}
+	case s3types.ChecksumAlgorithmCrc32c:
+		sum := crc32.Checksum(concatenated, crc32.MakeTable(crc32.Castagnoli))
+		digest = []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}
+	case s3types.ChecksumAlgorithmSha1:
+		sum := sha1.Sum(concatenated)
+		digest = sum[:]
+	case s3types.ChecksumAlgorithmSha256:
+		sum := sha256.Sum256(concatenated)
+		digest = sum[:]
+	default:
+		return ""
+	}
+
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(digest), len(parts))
+}
+
+// crc32CombineAll folds every part's CRC32/CRC32C into a single full-object
+// checksum using the standard CRC transition-matrix combine algorithm, as if
+// the parts' bytes had been hashed back-to-back in one pass.
+func crc32CombineAll(poly uint32, parts []partChecksum) []byte {
+	var crc uint32
+	var seen bool
+	for _, p := range parts {
+		partCRC := uint32(p.raw[0])<<24 | uint32(p.raw[1])<<16 | uint32(p.raw[2])<<8 | uint32(p.raw[3])
+		if !seen {
+			crc = partCRC
+			seen = true
+			continue
+		}
+		crc = crc32Combine(poly, crc, partCRC, p.length)
+	}
+	return []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
+
+// crc32Combine computes the CRC of two concatenated byte sequences given only
+// crc1 (the CRC of the first sequence), crc2 (the CRC of the second), and
+// len2 (the length of the second sequence), via GF(2) matrix exponentiation
+// over the CRC's bit-transition matrix. This mirrors zlib's crc32_combine.
+func crc32Combine(poly uint32, crc1, crc2 uint32, len2 int64) uint32 {
+	if len2 == 0 {
+		return crc1
+	}
+
+	// odd holds the transition matrix for shifting the CRC register by one
+	// zero bit; even holds the matrix for shifting it by two zero bits.
+	var even, odd [32]uint32
+
+	odd[0] = poly
+	row := uint32(1)
+	for i := 1; i < 32; i++ {
+		odd[i] = row
+		row <<= 1
+	}
+
+	gf2MatrixSquare(&even, &odd)
+	gf2MatrixSquare(&odd, &even)
+
+	crc := crc1
+	n := uint64(len2)
+	for n != 0 {
+		gf2MatrixSquare(&even, &odd)
+		if n&1 != 0 {
+			crc = gf2MatrixTimes(&even, crc)
+		}
+		n >>= 1
+		if n == 0 {
+			break
+		}
+
+		gf2MatrixSquare(&odd, &even)
+		if n&1 != 0 {
+			crc = gf2MatrixTimes(&odd, crc)
+		}
+		n >>= 1
+	}
+
+	return crc ^ crc2
+}
+
+func gf2MatrixTimes(mat *[32]uint32, vec uint32) uint32 {
+	var sum uint32
+	i := 0
+	for vec != 0 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+		vec >>= 1
+		i++
+	}
+	return sum
+}
+
+func gf2MatrixSquare(square, mat *[32]uint32) {
+	for i := 0; i < 32; i++ {
+		square[i] = gf2MatrixTimes(mat, mat[i])
+	}
+}
@@ -0,0 +1,121 @@
+package transfermanager
+
+import (
+	"encoding/base64"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestCrc32Combine(t *testing.T) {
+	cases := []struct {
+		name string
+		poly uint32
+		data []byte
+		cut  int // split point between the two parts
+	}{
+		{"ieee even split", crc32.IEEE, []byte("the quick brown fox jumps over the lazy dog"), 22},
+		{"castagnoli even split", crc32.Castagnoli, []byte("the quick brown fox jumps over the lazy dog"), 22},
+		{"first part empty", crc32.IEEE, []byte("abc"), 0},
+		{"second part empty", crc32.IEEE, []byte("abc"), 3},
+		{"single byte parts", crc32.Castagnoli, []byte("ab"), 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			table := crc32.MakeTable(tc.poly)
+			part1, part2 := tc.data[:tc.cut], tc.data[tc.cut:]
+
+			crc1 := crc32.Checksum(part1, table)
+			crc2 := crc32.Checksum(part2, table)
+			want := crc32.Checksum(tc.data, table)
+
+			got := crc32Combine(tc.poly, crc1, crc2, int64(len(part2)))
+			if got != want {
+				t.Errorf("crc32Combine() = %#x, want %#x", got, want)
+			}
+		})
+	}
+}
+
+func TestCrc32CombineAll(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	splits := [][]byte{data[:10], data[10:25], data[25:]}
+
+	var parts []partChecksum
+	for _, s := range splits {
+		sum := crc32.ChecksumIEEE(s)
+		parts = append(parts, partChecksum{
+			algorithm: s3types.ChecksumAlgorithmCrc32,
+			raw:       []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)},
+			length:    int64(len(s)),
+		})
+	}
+
+	got := crc32CombineAll(crc32.IEEE, parts)
+	want := crc32.ChecksumIEEE(data)
+	gotSum := uint32(got[0])<<24 | uint32(got[1])<<16 | uint32(got[2])<<8 | uint32(got[3])
+	if gotSum != want {
+		t.Errorf("crc32CombineAll() = %#x, want %#x", gotSum, want)
+	}
+}
+
+func TestVerifyChecksumFullObject(t *testing.T) {
+	data := []byte("the quick brown fox jumps over the lazy dog")
+	splits := [][]byte{data[:10], data[10:25], data[25:]}
+
+	acc := &checksumAccumulator{}
+	for i, s := range splits {
+		sum := crc32.ChecksumIEEE(s)
+		raw := []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}
+		acc.add(int64(i), partChecksum{algorithm: s3types.ChecksumAlgorithmCrc32, raw: raw, length: int64(len(s))})
+	}
+
+	want := base64.StdEncoding.EncodeToString(func() []byte {
+		sum := crc32.ChecksumIEEE(data)
+		return []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}
+	}())
+
+	actual, err := verifyChecksum(acc, want)
+	if err != nil {
+		t.Fatalf("verifyChecksum() error = %v", err)
+	}
+	if actual != want {
+		t.Errorf("verifyChecksum() = %q, want %q", actual, want)
+	}
+}
+
+func TestVerifyChecksumComposite(t *testing.T) {
+	part1 := []byte("hello ")
+	part2 := []byte("world")
+
+	acc := &checksumAccumulator{}
+	sum1 := crc32.ChecksumIEEE(part1)
+	sum2 := crc32.ChecksumIEEE(part2)
+	acc.add(0, partChecksum{algorithm: s3types.ChecksumAlgorithmCrc32, raw: []byte{byte(sum1 >> 24), byte(sum1 >> 16), byte(sum1 >> 8), byte(sum1)}, length: int64(len(part1))})
+	acc.add(1, partChecksum{algorithm: s3types.ChecksumAlgorithmCrc32, raw: []byte{byte(sum2 >> 24), byte(sum2 >> 16), byte(sum2 >> 8), byte(sum2)}, length: int64(len(part2))})
+
+	want := compositeChecksum(s3types.ChecksumAlgorithmCrc32, acc.ordered())
+
+	actual, err := verifyChecksum(acc, want)
+	if err != nil {
+		t.Fatalf("verifyChecksum() error = %v", err)
+	}
+	if actual != want {
+		t.Errorf("verifyChecksum() = %q, want %q", actual, want)
+	}
+}
+
+func TestVerifyChecksumMismatch(t *testing.T) {
+	acc := &checksumAccumulator{}
+	sum := crc32.ChecksumIEEE([]byte("data"))
+	acc.add(0, partChecksum{algorithm: s3types.ChecksumAlgorithmCrc32, raw: []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}, length: 4})
+
+	_, err := verifyChecksum(acc, "not-the-right-checksum")
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("verifyChecksum() error = %v, want a *ChecksumMismatchError", err)
+	}
+}
@@ -0,0 +1,22 @@
+package transfermanager
+
+// errReadingBody is returned internally by tryDownloadChunk when copying a
+// part's response body fails partway through. downloadChunk unwraps it to
+// decide whether and how to retry the part.
+type errReadingBody struct {
+	err error
+
+	// bytesWritten is how many bytes of this chunk's range were
+	// successfully copied to the destination before err occurred. A retry
+	// only needs to fetch the remaining bytes=start+bytesWritten-end
+	// sub-range instead of the chunk's whole range again.
+	bytesWritten int64
+}
+
+func (e *errReadingBody) Error() string {
+	return "failed to read part body: " + e.err.Error()
+}
+
+func (e *errReadingBody) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,83 @@
+package transfermanager
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressListener receives incremental byte counts as a DownloadObject call
+// reads an object's body. OnBytes may be called concurrently from the
+// goroutines downloading separate parts of the same object, and must be
+// safe to call that way.
+type ProgressListener interface {
+	// OnBytes reports that bytesJustRead more bytes of objectKey have been
+	// read. totalBytes is the full size of the object as discovered from
+	// the first part response; it is 0 until that response is parsed.
+	OnBytes(objectKey string, bytesJustRead, totalBytes int64)
+}
+
+// ReadLimiter throttles the rate bytes are read from S3. It matches the
+// signature of golang.org/x/time/rate.Limiter's WaitN method so that type can
+// be used directly as a ReadLimiter, but callers are free to provide their
+// own implementation (for example to share one throughput cap across many
+// concurrent DownloadObject / DownloadDirectory calls).
+type ReadLimiter interface {
+	// WaitN blocks until n bytes are permitted to be read, or ctx is done.
+	WaitN(ctx context.Context, n int) error
+}
+
+// progressReader wraps a part's response body, reporting bytes read to a
+// ProgressListener and blocking on a ReadLimiter before each read returns,
+// so both progress reporting and bandwidth throttling apply uniformly
+// across the parallel chunk goroutines in downloader.
+type progressReader struct {
+	ctx context.Context
+	r   io.Reader
+
+	objectKey  string
+	listener   ProgressListener
+	limiter    ReadLimiter
+	totalBytes func() int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		if p.limiter != nil {
+			if werr := p.limiter.WaitN(p.ctx, n); werr != nil {
+				return n, werr
+			}
+		}
+		if p.listener != nil {
+			p.listener.OnBytes(p.objectKey, int64(n), p.totalBytes())
+		}
+	}
+	return n, err
+}
+
+// wrapProgress returns r wrapped so reads go through the downloader's
+// configured ProgressListener and ReadLimiter, if any are set. The input's
+// fields take precedence over the client's Options defaults, allowing a
+// single shared ReadLimiter to be overridden per call when needed.
+func (d *downloader) wrapProgress(ctx context.Context, r io.Reader) io.Reader {
+	listener := d.in.ProgressListener
+	if listener == nil {
+		listener = d.options.ProgressListener
+	}
+	limiter := d.in.ReadLimiter
+	if limiter == nil {
+		limiter = d.options.ReadLimiter
+	}
+	if listener == nil && limiter == nil {
+		return r
+	}
+
+	return &progressReader{
+		ctx:        ctx,
+		r:          r,
+		objectKey:  d.in.Key,
+		listener:   listener,
+		limiter:    limiter,
+		totalBytes: d.getTotalBytes,
+	}
+}
@@ -0,0 +1,214 @@
+package transfermanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// RestoreTier selects the Glacier / Deep Archive restore speed, trading cost
+// for how quickly the object becomes available. See [RestoreObject] for
+// details on each tier's latency.
+//
+// [RestoreObject]: https://docs.aws.amazon.com/AmazonS3/latest/API/API_RestoreObject.html
+type RestoreTier string
+
+// Enumeration of RestoreTier values.
+const (
+	RestoreTierBulk      RestoreTier = "Bulk"
+	RestoreTierStandard  RestoreTier = "Standard"
+	RestoreTierExpedited RestoreTier = "Expedited"
+)
+
+// RestoreOnDemand configures DownloadObject to transparently restore an
+// archived (Glacier / Deep Archive / Intelligent-Tiering Archive) object
+// before downloading it, instead of failing with InvalidObjectState.
+type RestoreOnDemand struct {
+	// Tier is the restore speed/cost tier to request.
+	Tier RestoreTier
+
+	// Days the restored copy remains accessible before S3 returns it to the
+	// archive tier.
+	Days int32
+
+	// PollInterval is how often HeadObject is polled while a restore is in
+	// progress. Defaults to 30 seconds when zero.
+	PollInterval time.Duration
+}
+
+func (r *RestoreOnDemand) pollInterval() time.Duration {
+	if r.PollInterval > 0 {
+		return r.PollInterval
+	}
+	return 30 * time.Second
+}
+
+// ErrRestoreTimeout is returned by DownloadObject when the caller's context
+// is done while waiting for an archived object's restore to complete.
+type ErrRestoreTimeout struct {
+	Bucket string
+	Key    string
+}
+
+func (e *ErrRestoreTimeout) Error() string {
+	return fmt.Sprintf("timed out waiting for restore of s3://%s/%s to complete", e.Bucket, e.Key)
+}
+
+// RestoreProgressListener is an optional interface a ProgressListener may
+// additionally implement to observe restore state transitions while
+// RestoreOnDemand is waiting for an archived object to become available.
+type RestoreProgressListener interface {
+	// OnRestoreStatus reports that objectKey's restore is still ongoing (or
+	// has just completed, when ongoing is false).
+	OnRestoreStatus(objectKey string, tier RestoreTier, ongoing bool)
+}
+
+func (d *downloader) reportRestoreStatus(ongoing bool) {
+	listener := d.in.ProgressListener
+	if listener == nil {
+		listener = d.options.ProgressListener
+	}
+	if rl, ok := listener.(RestoreProgressListener); ok {
+		rl.OnRestoreStatus(d.in.Key, d.in.RestoreOnDemand.Tier, ongoing)
+	}
+}
+
+// isArchiveStorageClass reports whether sc requires a restore before its
+// object's bytes can be read via GetObject. StorageClassGlacierIr (Glacier
+// Instant Retrieval) is deliberately excluded: unlike Glacier and Deep
+// Archive, it's directly GetObject-able, and requesting a restore for it
+// just fails with InvalidObjectState.
+func isArchiveStorageClass(sc s3types.StorageClass) bool {
+	switch sc {
+	case s3types.StorageClassGlacier, s3types.StorageClassDeepArchive:
+		return true
+	}
+	return false
+}
+
+// isArchiveAccessTier reports whether an Intelligent-Tiering object has
+// moved into one of the archive access tiers, which - unlike the rest of
+// Intelligent-Tiering - also need a restore before GetObject works. This
+// isn't visible on StorageClass (Intelligent-Tiering objects always report
+// StorageClassIntelligentTiering); S3 surfaces it separately as
+// HeadObjectOutput.ArchiveStatus.
+func isArchiveAccessTier(status s3types.ArchiveStatus) bool {
+	switch status {
+	case s3types.ArchiveStatusArchiveAccess, s3types.ArchiveStatusDeepArchiveAccess:
+		return true
+	}
+	return false
+}
+
+// parseRestoreHeader extracts the ongoing-request value from an x-amz-restore
+// header value, e.g. `ongoing-request="true"` or
+// `ongoing-request="false", expiry-date="..."`.
+func parseRestoreHeader(restore string) (ongoing bool, present bool) {
+	if restore == "" {
+		return false, false
+	}
+	return strings.Contains(restore, `ongoing-request="true"`), true
+}
+
+// ensureRestored checks whether the object needs restoring from an archive
+// storage tier and, if so, issues RestoreObject and polls HeadObject until
+// the restore completes or ctx is done.
+func (d *downloader) ensureRestored(ctx context.Context, clientOptions ...func(*s3.Options)) error {
+	if d.in.RestoreOnDemand == nil {
+		return nil
+	}
+
+	head, err := d.options.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(d.in.Bucket),
+		Key:       aws.String(d.in.Key),
+		VersionId: nzstring(d.in.VersionID),
+	}, clientOptions...)
+	if err != nil {
+		return err
+	}
+
+	if !isArchiveStorageClass(head.StorageClass) && !isArchiveAccessTier(head.ArchiveStatus) {
+		return nil
+	}
+
+	ongoing, present := parseRestoreHeader(aws.ToString(head.Restore))
+	if present && !ongoing {
+		// Already restored.
+		return nil
+	}
+
+	if !present {
+		if err := d.startRestore(ctx, clientOptions...); err != nil {
+			return err
+		}
+	}
+
+	return d.waitForRestore(ctx, clientOptions...)
+}
+
+func (d *downloader) startRestore(ctx context.Context, clientOptions ...func(*s3.Options)) error {
+	r := d.in.RestoreOnDemand
+	_, err := d.options.S3.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket:    aws.String(d.in.Bucket),
+		Key:       aws.String(d.in.Key),
+		VersionId: nzstring(d.in.VersionID),
+		RestoreRequest: &s3types.RestoreRequest{
+			Days: aws.Int32(r.Days),
+			GlacierJobParameters: &s3types.GlacierJobParameters{
+				Tier: s3types.Tier(r.Tier),
+			},
+		},
+	}, clientOptions...)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *downloader) waitForRestore(ctx context.Context, clientOptions ...func(*s3.Options)) error {
+	r := d.in.RestoreOnDemand
+	d.reportRestoreStatus(true)
+
+	ticker := time.NewTicker(r.pollInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return &ErrRestoreTimeout{Bucket: d.in.Bucket, Key: d.in.Key}
+		case <-ticker.C:
+			head, err := d.options.S3.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket:    aws.String(d.in.Bucket),
+				Key:       aws.String(d.in.Key),
+				VersionId: nzstring(d.in.VersionID),
+			}, clientOptions...)
+			if err != nil {
+				return err
+			}
+
+			ongoing, present := parseRestoreHeader(aws.ToString(head.Restore))
+			if present && !ongoing {
+				d.reportRestoreStatus(false)
+				return nil
+			}
+		}
+	}
+}
+
+// isInvalidObjectState reports whether err is the InvalidObjectState error
+// S3 returns from GetObject when an object still needs restoring.
+func isInvalidObjectState(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidObjectState"
+}
@@ -0,0 +1,235 @@
+package transfermanager
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// RangeScheduler controls how the chunked download path maps "the next byte
+// offset to fetch" onto a dlChunk. The default is sequentialScheduler, which
+// steps through the object PartSizeBytes at a time exactly as before this
+// was made pluggable; ConsistentHashingScheduler is an alternative that
+// targets a specific cache/proxy endpoint per range.
+type RangeScheduler interface {
+	// Next returns the dlChunk to fetch next given the object's total size
+	// and the current cursor position, along with that chunk's end-of-range
+	// (exclusive) to use as the next call's pos. ok is false once pos has
+	// covered the whole object.
+	Next(total, pos int64) (chunk dlChunk, nextPos int64, ok bool)
+
+	// OnComplete is called once a chunk returned by Next has finished
+	// downloading, successfully or not, so stateful schedulers (e.g. one
+	// tracking per-endpoint failures) can adapt.
+	OnComplete(chunk dlChunk, err error)
+
+	// Retry returns an updated chunk to re-download after chunk has just
+	// failed (and already been reported via OnComplete), and whether
+	// there's anywhere left to retry it. Schedulers with no failover beyond
+	// Next's initial placement, like sequentialScheduler, always return
+	// ok=false, leaving the failure to propagate as before.
+	Retry(chunk dlChunk) (retry dlChunk, ok bool)
+}
+
+// sequentialScheduler is the default RangeScheduler: it steps through the
+// object partSize() bytes at a time, identical to the downloader's behavior
+// before RangeScheduler existed. partSize is a func rather than a fixed
+// int64 so it can read the downloader's adaptiveController, whose target
+// part size grows over the course of the download, instead of pinning
+// every chunk to whatever size was in effect when the scheduler was built.
+type sequentialScheduler struct {
+	partSize func() int64
+}
+
+func newSequentialScheduler(partSize func() int64) *sequentialScheduler {
+	return &sequentialScheduler{partSize: partSize}
+}
+
+func (s *sequentialScheduler) Next(total, pos int64) (dlChunk, int64, bool) {
+	if pos >= total {
+		return dlChunk{}, pos, false
+	}
+
+	end := pos + s.partSize() - 1
+	if end > total-1 {
+		end = total - 1
+	}
+
+	return dlChunk{withRange: fmt.Sprintf("bytes=%d-%d", pos, end)}, end + 1, true
+}
+
+func (s *sequentialScheduler) OnComplete(dlChunk, error) {}
+
+func (s *sequentialScheduler) Retry(dlChunk) (dlChunk, bool) { return dlChunk{}, false }
+
+// CacheEndpoint is one cache/proxy/accelerator node a ConsistentHashingScheduler
+// can route range requests to.
+type CacheEndpoint struct {
+	// Name identifies this endpoint on the hash ring and in logs/errors.
+	Name string
+
+	// ClientOptions rewrites an s3.Options (typically BaseEndpoint) to
+	// target this node instead of origin S3.
+	ClientOptions func(*s3.Options)
+}
+
+// ConsistentHashingScheduler assigns each byte range to one of a set of
+// cache/proxy endpoints (pull-through caches, regional accelerators) using
+// consistent hashing on (bucket, key, start), so repeated downloads of the
+// same range consistently hit the same node and benefit from its cache. On
+// a per-chunk failure it falls back to the next node on the ring, and
+// finally to origin S3 if every cache node has failed for that range.
+type ConsistentHashingScheduler struct {
+	Bucket, Key string
+	PartSize    int64
+	Endpoints   []CacheEndpoint
+
+	// VirtualNodes controls how many ring positions each endpoint occupies;
+	// more virtual nodes means a more even distribution across endpoints.
+	// Defaults to 100 when zero.
+	VirtualNodes int
+
+	ring     []ringPoint
+	byName   map[string]CacheEndpoint
+	mu       sync.Mutex
+	attempts map[string]int // range key -> number of endpoints already tried
+}
+
+type ringPoint struct {
+	hash     uint32
+	endpoint string
+}
+
+func (s *ConsistentHashingScheduler) ensureRing() {
+	if s.ring != nil {
+		return
+	}
+
+	virtualNodes := s.VirtualNodes
+	if virtualNodes <= 0 {
+		virtualNodes = 100
+	}
+
+	s.byName = make(map[string]CacheEndpoint, len(s.Endpoints))
+	s.attempts = make(map[string]int)
+	for _, ep := range s.Endpoints {
+		s.byName[ep.Name] = ep
+		for i := 0; i < virtualNodes; i++ {
+			s.ring = append(s.ring, ringPoint{
+				hash:     hashKey(fmt.Sprintf("%s#%d", ep.Name, i)),
+				endpoint: ep.Name,
+			})
+		}
+	}
+	sort.Slice(s.ring, func(i, j int) bool { return s.ring[i].hash < s.ring[j].hash })
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// nodesFor returns the endpoints for a range key in ring order, starting
+// from the primary node the hash lands on.
+func (s *ConsistentHashingScheduler) nodesFor(rangeKey string) []string {
+	if len(s.ring) == 0 {
+		return nil
+	}
+
+	h := hashKey(rangeKey)
+	start := sort.Search(len(s.ring), func(i int) bool { return s.ring[i].hash >= h })
+
+	seen := make(map[string]bool, len(s.byName))
+	var order []string
+	for i := 0; i < len(s.ring); i++ {
+		p := s.ring[(start+i)%len(s.ring)]
+		if seen[p.endpoint] {
+			continue
+		}
+		seen[p.endpoint] = true
+		order = append(order, p.endpoint)
+	}
+	return order
+}
+
+// Next implements RangeScheduler.
+func (s *ConsistentHashingScheduler) Next(total, pos int64) (dlChunk, int64, bool) {
+	s.ensureRing()
+
+	if pos >= total {
+		return dlChunk{}, pos, false
+	}
+
+	end := pos + s.PartSize - 1
+	if end > total-1 {
+		end = total - 1
+	}
+
+	rangeKey := fmt.Sprintf("%s/%s#%d", s.Bucket, s.Key, pos)
+	nodes := s.nodesFor(rangeKey)
+
+	s.mu.Lock()
+	attempt := s.attempts[rangeKey]
+	s.mu.Unlock()
+
+	chunk := dlChunk{withRange: fmt.Sprintf("bytes=%d-%d", pos, end)}
+	if attempt < len(nodes) {
+		ep := s.byName[nodes[attempt]]
+		chunk.clientOptions = ep.ClientOptions
+	}
+	// attempt >= len(nodes) falls back to origin S3 (chunk.clientOptions left nil).
+
+	return chunk, end + 1, true
+}
+
+// OnComplete implements RangeScheduler. On failure it advances to the next
+// node on the ring for this range, so a subsequent Retry call for the same
+// range targets a different endpoint.
+func (s *ConsistentHashingScheduler) OnComplete(chunk dlChunk, err error) {
+	if err == nil {
+		return
+	}
+
+	start, _, ok := parseRangeHeader(chunk.withRange)
+	if !ok {
+		return
+	}
+	rangeKey := fmt.Sprintf("%s/%s#%d", s.Bucket, s.Key, start)
+
+	s.mu.Lock()
+	s.attempts[rangeKey]++
+	s.mu.Unlock()
+}
+
+// Retry implements RangeScheduler. It re-targets chunk at the next node on
+// the ring for its range, per the attempt count OnComplete already
+// advanced, falling back to origin S3 once every cache node has failed and
+// reporting ok=false only once that origin attempt has failed too.
+func (s *ConsistentHashingScheduler) Retry(chunk dlChunk) (dlChunk, bool) {
+	start, _, ok := parseRangeHeader(chunk.withRange)
+	if !ok {
+		return dlChunk{}, false
+	}
+	rangeKey := fmt.Sprintf("%s/%s#%d", s.Bucket, s.Key, start)
+	nodes := s.nodesFor(rangeKey)
+
+	s.mu.Lock()
+	attempt := s.attempts[rangeKey]
+	s.mu.Unlock()
+
+	if attempt > len(nodes) {
+		return dlChunk{}, false
+	}
+
+	retry := chunk
+	if attempt < len(nodes) {
+		retry.clientOptions = s.byName[nodes[attempt]].ClientOptions
+	} else {
+		retry.clientOptions = nil // every cache node has failed; fall back to origin S3
+	}
+	return retry, true
+}
@@ -0,0 +1,160 @@
+package transfermanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func testEndpoints() []CacheEndpoint {
+	return []CacheEndpoint{
+		{Name: "node-a", ClientOptions: func(o *s3.Options) { o.BaseEndpoint = aws.String("https://node-a") }},
+		{Name: "node-b", ClientOptions: func(o *s3.Options) { o.BaseEndpoint = aws.String("https://node-b") }},
+		{Name: "node-c", ClientOptions: func(o *s3.Options) { o.BaseEndpoint = aws.String("https://node-c") }},
+	}
+}
+
+func TestConsistentHashingSchedulerStableAssignment(t *testing.T) {
+	s := &ConsistentHashingScheduler{Bucket: "b", Key: "k", PartSize: 100, Endpoints: testEndpoints()}
+
+	_, next, ok := s.Next(1000, 0)
+	if !ok {
+		t.Fatal("Next() ok = false, want true")
+	}
+	if next != 100 {
+		t.Errorf("Next() nextPos = %d, want 100", next)
+	}
+
+	// A fresh scheduler built from the same bucket/key/endpoints must land
+	// the same range on the same primary node, since that's the whole point
+	// of consistent hashing.
+	s2 := &ConsistentHashingScheduler{Bucket: "b", Key: "k", PartSize: 100, Endpoints: testEndpoints()}
+	s2.Next(1000, 0)
+
+	s.ensureRing()
+	s2.ensureRing()
+	nodes1 := s.nodesFor("b/k#0")
+	nodes2 := s2.nodesFor("b/k#0")
+	if len(nodes1) == 0 || nodes1[0] != nodes2[0] {
+		t.Errorf("nodesFor() primary node = %v, %v, want matching first elements", nodes1, nodes2)
+	}
+}
+
+func TestConsistentHashingSchedulerExhaustsObject(t *testing.T) {
+	s := &ConsistentHashingScheduler{Bucket: "b", Key: "k", PartSize: 100, Endpoints: testEndpoints()}
+
+	var pos int64
+	var chunks int
+	for {
+		_, next, ok := s.Next(250, pos)
+		if !ok {
+			break
+		}
+		pos = next
+		chunks++
+		if chunks > 10 {
+			t.Fatal("Next() never reported ok=false")
+		}
+	}
+	if chunks != 3 {
+		t.Errorf("got %d chunks for a 250-byte object with PartSize 100, want 3", chunks)
+	}
+}
+
+func TestConsistentHashingSchedulerRetryFallsBackToOrigin(t *testing.T) {
+	s := &ConsistentHashingScheduler{Bucket: "b", Key: "k", PartSize: 100, Endpoints: testEndpoints()}
+
+	chunk, _, ok := s.Next(1000, 0)
+	if !ok {
+		t.Fatal("Next() ok = false, want true")
+	}
+
+	// Next already placed the chunk on the first node; Retry should still
+	// offer one more attempt per remaining cache node, plus one final
+	// fallback to origin S3, before giving up.
+	retries := 0
+	var lastClientOptions *func(*s3.Options)
+	for {
+		s.OnComplete(chunk, errors.New("boom"))
+		retry, ok := s.Retry(chunk)
+		if !ok {
+			break
+		}
+		chunk = retry
+		lastClientOptions = &chunk.clientOptions
+		retries++
+		if retries > len(testEndpoints())+1 {
+			t.Fatal("Retry() kept offering retries past every endpoint and origin S3")
+		}
+	}
+	if retries != len(testEndpoints()) {
+		t.Errorf("got %d retries, want %d (one per remaining cache node, plus the origin-S3 fallback)", retries, len(testEndpoints()))
+	}
+	if lastClientOptions != nil && *lastClientOptions != nil {
+		t.Error("final retry's clientOptions is non-nil, want nil (origin S3 fallback)")
+	}
+}
+
+func TestConsistentHashingSchedulerRetryWithoutFailureIsNoop(t *testing.T) {
+	s := &ConsistentHashingScheduler{Bucket: "b", Key: "k", PartSize: 100, Endpoints: testEndpoints()}
+
+	chunk, _, ok := s.Next(1000, 0)
+	if !ok {
+		t.Fatal("Next() ok = false, want true")
+	}
+
+	s.OnComplete(chunk, nil)
+	retry, ok := s.Retry(chunk)
+	if !ok {
+		t.Fatal("Retry() ok = false after a successful OnComplete, want true (re-offers the node Next chose)")
+	}
+
+	var want, got s3.Options
+	chunk.clientOptions(&want)
+	retry.clientOptions(&got)
+	if aws.ToString(got.BaseEndpoint) != aws.ToString(want.BaseEndpoint) {
+		t.Errorf("Retry() targets %q, want %q (the node Next chose, since no failure was reported)", aws.ToString(got.BaseEndpoint), aws.ToString(want.BaseEndpoint))
+	}
+}
+
+func TestSequentialSchedulerRetryNeverRetries(t *testing.T) {
+	s := newSequentialScheduler(func() int64 { return 100 })
+	chunk, _, ok := s.Next(1000, 0)
+	if !ok {
+		t.Fatal("Next() ok = false, want true")
+	}
+
+	s.OnComplete(chunk, errors.New("boom"))
+	if _, ok := s.Retry(chunk); ok {
+		t.Error("sequentialScheduler.Retry() ok = true, want false")
+	}
+}
+
+// TestSequentialSchedulerReadsPartSizeOnEveryCall proves sequentialScheduler
+// doesn't pin the part size it was built with: a growing func() int64 (as
+// adaptiveController.currentPartSize is, under AdaptiveConcurrency) changes
+// the range size Next hands out, instead of every chunk staying the size in
+// effect at newSequentialScheduler time.
+func TestSequentialSchedulerReadsPartSizeOnEveryCall(t *testing.T) {
+	partSize := int64(100)
+	s := newSequentialScheduler(func() int64 { return partSize })
+
+	_, next, ok := s.Next(1000, 0)
+	if !ok {
+		t.Fatal("Next() ok = false, want true")
+	}
+	if next != 100 {
+		t.Fatalf("first Next() nextPos = %d, want 100", next)
+	}
+
+	partSize = 300
+	_, next, ok = s.Next(1000, next)
+	if !ok {
+		t.Fatal("Next() ok = false, want true")
+	}
+	if next != 400 {
+		t.Errorf("Next() nextPos after growing partSize = %d, want 400 (100 + grown 300)", next)
+	}
+}
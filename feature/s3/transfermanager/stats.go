@@ -0,0 +1,172 @@
+package transfermanager
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressEventType identifies the kind of event delivered to a download's
+// Progress callback.
+type ProgressEventType string
+
+// Enumeration of ProgressEventType values.
+const (
+	// PartStarted is emitted just before a part's GetObject request is sent.
+	PartStarted ProgressEventType = "PartStarted"
+
+	// PartBytes is emitted as a part's body is read. It is throttled to at
+	// most once per progressBytesInterval per part, rather than once per
+	// read, to keep the callback cheap on fast connections.
+	PartBytes ProgressEventType = "PartBytes"
+
+	// PartRetried is emitted each time a part is retried after a body read
+	// error, or after the object was restored on demand following an
+	// InvalidObjectState error.
+	PartRetried ProgressEventType = "PartRetried"
+
+	// PartCompleted is emitted once a part finishes, successfully or not.
+	PartCompleted ProgressEventType = "PartCompleted"
+
+	// DownloadCompleted is emitted once, after every part has finished.
+	DownloadCompleted ProgressEventType = "DownloadCompleted"
+)
+
+// ProgressEvent is one state transition reported to a download's Progress
+// callback. Not every field is meaningful for every Type; see the
+// ProgressEventType constants for which fields each event carries.
+type ProgressEvent struct {
+	Type ProgressEventType
+
+	ObjectKey string
+
+	// Part is the part's zero-based index for PartStarted/PartBytes/
+	// PartRetried/PartCompleted; it is unset for DownloadCompleted.
+	Part int64
+
+	// Bytes is the incremental bytes read for PartBytes, or the part's/
+	// object's total size for PartCompleted/DownloadCompleted.
+	Bytes int64
+
+	// TotalBytes is the full object size, once known.
+	TotalBytes int64
+
+	// Duration is set on PartCompleted and DownloadCompleted.
+	Duration time.Duration
+
+	// Attempts is the number of GetObject calls this part required,
+	// including the final successful one. Set on PartCompleted.
+	Attempts int
+
+	// Err is set on PartCompleted when the part ultimately failed.
+	Err error
+}
+
+// progressBytesInterval bounds how often PartBytes events fire for a single
+// part, so a fast connection doesn't turn progress reporting into the
+// bottleneck.
+const progressBytesInterval = 200 * time.Millisecond
+
+func (d *downloader) emitProgress(ev ProgressEvent) {
+	progress := d.in.Progress
+	if progress == nil {
+		progress = d.options.Progress
+	}
+	if progress == nil {
+		return
+	}
+	ev.ObjectKey = d.in.Key
+	ev.TotalBytes = d.getTotalBytes()
+	progress(ev)
+}
+
+// Stats is a point-in-time snapshot of a download's aggregate throughput and
+// per-part telemetry.
+type Stats struct {
+	// TotalBytesWritten is how many bytes have been written so far.
+	TotalBytesWritten int64
+
+	// Elapsed is how long the download has been running.
+	Elapsed time.Duration
+
+	// ThroughputBytesPerSec is TotalBytesWritten / Elapsed.Seconds().
+	ThroughputBytesPerSec float64
+
+	// PartLatencies holds one entry per completed part, in completion
+	// order, regardless of how many retries it took.
+	PartLatencies []time.Duration
+
+	// Retries is the total number of part retries across the download.
+	Retries int
+
+	// BytesInFlight is how many bytes are currently buffered in parts whose
+	// GetObject response has been received but not yet fully copied out.
+	BytesInFlight int64
+}
+
+// statsRecorder accumulates the counters behind Stats as parts complete.
+type statsRecorder struct {
+	start time.Time
+
+	mu            sync.Mutex
+	partLatencies []time.Duration
+	retries       int
+	bytesInFlight int64
+}
+
+func newStatsRecorder() *statsRecorder {
+	return &statsRecorder{start: time.Now()}
+}
+
+func (s *statsRecorder) recordRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+func (s *statsRecorder) recordPartLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.partLatencies = append(s.partLatencies, d)
+}
+
+func (s *statsRecorder) addBytesInFlight(delta int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bytesInFlight += delta
+}
+
+func (s *statsRecorder) snapshot(totalBytesWritten int64) Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := time.Since(s.start)
+	var throughput float64
+	if elapsed > 0 {
+		throughput = float64(totalBytesWritten) / elapsed.Seconds()
+	}
+
+	latencies := make([]time.Duration, len(s.partLatencies))
+	copy(latencies, s.partLatencies)
+
+	return Stats{
+		TotalBytesWritten:     totalBytesWritten,
+		Elapsed:               elapsed,
+		ThroughputBytesPerSec: throughput,
+		PartLatencies:         latencies,
+		Retries:               s.retries,
+		BytesInFlight:         s.bytesInFlight,
+	}
+}
+
+// Stats returns a snapshot of this download's aggregate throughput and
+// per-part telemetry so far. It is safe to call concurrently with the
+// download in progress.
+func (d *downloader) Stats() Stats {
+	return d.stats.snapshot(d.getWritten())
+}
+
+func (d *downloader) getWritten() int64 {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.written
+}
@@ -0,0 +1,169 @@
+package aggregation
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// DefaultMaxBytes is the aggregated record size an Aggregator flushes at if
+// MaxBytes isn't set: Kinesis's 1 MiB per-record limit.
+const DefaultMaxBytes = 1 << 20
+
+// Aggregator packs logical (user) records into Kinesis records using the
+// KPL's aggregation format, buffering Add calls until a size or count
+// threshold is hit. Add, Flush and Len take an internal lock, so an
+// Aggregator is safe for concurrent use from multiple goroutines.
+type Aggregator struct {
+	// MaxBytes caps the encoded size, in bytes, of a flushed aggregated
+	// record. Defaults to DefaultMaxBytes.
+	MaxBytes int
+	// MaxRecords caps the number of logical records a flushed aggregated
+	// record may hold. Zero means unbounded (MaxBytes is then the only
+	// limit).
+	MaxRecords int
+
+	mu                   sync.Mutex
+	records              []Record
+	partitionKeys        []string
+	partitionKeyIndex    map[string]uint64
+	explicitHashKeys     []string
+	explicitHashKeyIndex map[string]uint64
+	size                 int
+}
+
+// NewAggregator returns an Aggregator with KPL-equivalent defaults.
+func NewAggregator() *Aggregator {
+	return &Aggregator{MaxBytes: DefaultMaxBytes}
+}
+
+// Add buffers a logical record for aggregation. If buffering it would push
+// the aggregator past MaxBytes or MaxRecords, the buffer built up so far is
+// flushed into a PutRecordsRequestEntry first, then the new record starts
+// the next buffer; full reports whether that happened. A single record
+// larger than MaxBytes on its own is still buffered - and flushed alone -
+// since it can't be split further.
+func (a *Aggregator) Add(partitionKey, explicitHashKey, data []byte) (full bool, flushed *types.PutRecordsRequestEntry, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r := Record{PartitionKey: string(partitionKey), ExplicitHashKey: string(explicitHashKey), Data: data}
+	addedSize := a.marginalSize(r)
+
+	maxBytes := a.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if len(a.records) > 0 && (len(magic)+a.size+addedSize+md5TrailerLen > maxBytes || (a.MaxRecords > 0 && len(a.records)+1 > a.MaxRecords)) {
+		entry, ferr := a.flushLocked()
+		if ferr != nil {
+			return false, nil, ferr
+		}
+		a.appendLocked(r, a.marginalSize(r))
+		return true, entry, nil
+	}
+
+	a.appendLocked(r, addedSize)
+	return false, nil, nil
+}
+
+// Flush builds a PutRecordsRequestEntry from whatever has been buffered so
+// far and resets the aggregator. It returns nil, nil if nothing is
+// buffered.
+func (a *Aggregator) Flush() (*types.PutRecordsRequestEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flushLocked()
+}
+
+// Len reports how many logical records are currently buffered.
+func (a *Aggregator) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.records)
+}
+
+func (a *Aggregator) appendLocked(r Record, size int) {
+	if a.partitionKeyIndex == nil {
+		a.partitionKeyIndex = make(map[string]uint64)
+		a.explicitHashKeyIndex = make(map[string]uint64)
+	}
+	if _, ok := a.partitionKeyIndex[r.PartitionKey]; !ok {
+		a.partitionKeyIndex[r.PartitionKey] = uint64(len(a.partitionKeys))
+		a.partitionKeys = append(a.partitionKeys, r.PartitionKey)
+	}
+	if r.ExplicitHashKey != "" {
+		if _, ok := a.explicitHashKeyIndex[r.ExplicitHashKey]; !ok {
+			a.explicitHashKeyIndex[r.ExplicitHashKey] = uint64(len(a.explicitHashKeys))
+			a.explicitHashKeys = append(a.explicitHashKeys, r.ExplicitHashKey)
+		}
+	}
+	a.records = append(a.records, r)
+	a.size += size
+}
+
+func (a *Aggregator) flushLocked() (*types.PutRecordsRequestEntry, error) {
+	if len(a.records) == 0 {
+		return nil, nil
+	}
+
+	var body protoWriter
+	for _, pk := range a.partitionKeys {
+		body.stringField(1, pk)
+	}
+	for _, ehk := range a.explicitHashKeys {
+		body.stringField(2, ehk)
+	}
+	for _, r := range a.records {
+		hasExplicitHashKey := r.ExplicitHashKey != ""
+		var rec protoWriter
+		encodeRecord(&rec, r, a.partitionKeyIndex[r.PartitionKey], a.explicitHashKeyIndex[r.ExplicitHashKey], hasExplicitHashKey)
+		body.bytesField(3, rec.Buf)
+	}
+
+	entry := &types.PutRecordsRequestEntry{
+		Data:         wrap(body.Buf),
+		PartitionKey: aws.String(a.partitionKeys[0]),
+	}
+	if a.records[0].ExplicitHashKey != "" {
+		entry.ExplicitHashKey = aws.String(a.records[0].ExplicitHashKey)
+	}
+
+	a.records = nil
+	a.partitionKeys = nil
+	a.partitionKeyIndex = nil
+	a.explicitHashKeys = nil
+	a.explicitHashKeyIndex = nil
+	a.size = 0
+
+	return entry, nil
+}
+
+// marginalSize estimates how many bytes r would add to the aggregated
+// record if flushed next, including a new partition/explicit-hash-key
+// table entry when r introduces one. It's an upper-bound estimate, not an
+// exact protobuf size, which is fine for deciding when to flush. The magic
+// prefix and MD5 trailer are not included; Add counts those once.
+func (a *Aggregator) marginalSize(r Record) int {
+	// 1-byte tag (every field number here is below 16) plus a length or
+	// index varint of at most 5 bytes (any value below 2^35).
+	const perFieldOverhead = 1 + 5
+
+	// The record's own entry in the records list, its partition_key_index
+	// field, and its data field.
+	size := len(r.Data) + 3*perFieldOverhead
+	if _, ok := a.partitionKeyIndex[r.PartitionKey]; !ok {
+		size += len(r.PartitionKey) + perFieldOverhead
+	}
+	if r.ExplicitHashKey != "" {
+		size += perFieldOverhead // explicit_hash_key_index field
+		if _, ok := a.explicitHashKeyIndex[r.ExplicitHashKey]; !ok {
+			size += len(r.ExplicitHashKey) + perFieldOverhead
+		}
+	}
+	for k, v := range r.Tags {
+		size += len(k) + len(v) + perFieldOverhead*3
+	}
+	return size
+}
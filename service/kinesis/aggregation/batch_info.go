@@ -0,0 +1,38 @@
+package aggregation
+
+import "github.com/aws/smithy-go/middleware"
+
+// AggregationBatchInfo records how a PutRecords request built by a
+// record-aggregating middleware mapped back to the caller's original
+// records, so a caller inspecting a partially-failed PutRecordsOutput can
+// tell which of their logical records landed in which response entry.
+type AggregationBatchInfo struct {
+	// Entries has one element per entry in the PutRecordsInput that was
+	// actually sent, in the same order, recording how many of the
+	// caller's logical records that entry aggregated.
+	Entries []AggregationBatchEntry
+}
+
+// AggregationBatchEntry describes one aggregated (or passed-through)
+// PutRecordsRequestEntry.
+type AggregationBatchEntry struct {
+	// RecordCount is how many logical records this entry packed together.
+	// It's 1 for a record that wasn't aggregated at all.
+	RecordCount int
+}
+
+type aggregationBatchInfoKey struct{}
+
+// SetAggregationBatchInfo stashes info in metadata for GetAggregationBatchInfo
+// to retrieve later, typically from a middleware's HandleInitialize/
+// HandleDeserialize after the request it describes has been built or sent.
+func SetAggregationBatchInfo(metadata *middleware.Metadata, info AggregationBatchInfo) {
+	metadata.Set(aggregationBatchInfoKey{}, info)
+}
+
+// GetAggregationBatchInfo retrieves the AggregationBatchInfo a
+// record-aggregating middleware stashed in metadata, if any.
+func GetAggregationBatchInfo(metadata middleware.Metadata) (AggregationBatchInfo, bool) {
+	v, ok := metadata.Get(aggregationBatchInfoKey{}).(AggregationBatchInfo)
+	return v, ok
+}
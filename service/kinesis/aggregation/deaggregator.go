@@ -0,0 +1,129 @@
+package aggregation
+
+import "fmt"
+
+// Deaggregator expands Kinesis records produced by an Aggregator (or any
+// other KPL-compatible producer) back into their constituent logical
+// records.
+type Deaggregator struct {
+	// SkipChecksum disables verifying an aggregated record's MD5 trailer
+	// before decoding it. Off by default; only useful against a source
+	// that's already otherwise trusted and for which the extra MD5 pass
+	// over every record is measurably expensive.
+	SkipChecksum bool
+}
+
+// NewDeaggregator returns a Deaggregator with checksum verification
+// enabled.
+func NewDeaggregator() *Deaggregator {
+	return &Deaggregator{}
+}
+
+// Deaggregate decodes data, a Kinesis record's payload, into the logical
+// records a producer packed into it with the KPL aggregation format. It
+// returns an error if data doesn't carry the aggregation magic prefix, if
+// SkipChecksum is false and the MD5 trailer doesn't match, or if the
+// protobuf payload itself is malformed.
+func (d *Deaggregator) Deaggregate(data []byte) (*AggregatedRecord, error) {
+	protobuf, err := d.unwrap(data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := decodeAggregatedRecordProto(protobuf)
+	if err != nil {
+		return nil, fmt.Errorf("decode aggregated record: %w", err)
+	}
+
+	out := &AggregatedRecord{Records: make([]Record, 0, len(msg.records))}
+	for i, rec := range msg.records {
+		if rec.partitionKeyIndex >= uint64(len(msg.partitionKeyTable)) {
+			return nil, fmt.Errorf("record %d: partition key index %d out of range", i, rec.partitionKeyIndex)
+		}
+		r := Record{
+			PartitionKey: msg.partitionKeyTable[rec.partitionKeyIndex],
+			Data:         rec.data,
+			Tags:         rec.tags,
+		}
+		if rec.hasExplicitHashKeyIndex {
+			if rec.explicitHashKeyIndex >= uint64(len(msg.explicitHashKeyTable)) {
+				return nil, fmt.Errorf("record %d: explicit hash key index %d out of range", i, rec.explicitHashKeyIndex)
+			}
+			r.ExplicitHashKey = msg.explicitHashKeyTable[rec.explicitHashKeyIndex]
+		}
+		out.Records = append(out.Records, r)
+	}
+	return out, nil
+}
+
+func (d *Deaggregator) unwrap(data []byte) ([]byte, error) {
+	if !d.SkipChecksum {
+		return unwrap(data)
+	}
+	if !IsAggregated(data) {
+		return nil, fmt.Errorf("data does not start with the KPL aggregation magic number")
+	}
+	return data[len(magic) : len(data)-md5TrailerLen], nil
+}
+
+type protobufAggregatedRecord struct {
+	partitionKeyTable    []string
+	explicitHashKeyTable []string
+	records              []protobufRecord
+}
+
+type protobufRecord struct {
+	partitionKeyIndex       uint64
+	hasExplicitHashKeyIndex bool
+	explicitHashKeyIndex    uint64
+	data                    []byte
+	tags                    map[string]string
+}
+
+func decodeAggregatedRecordProto(b []byte) (protobufAggregatedRecord, error) {
+	var msg protobufAggregatedRecord
+
+	for len(b) > 0 {
+		fieldNum, wireType, n, err := decodeProtoTag(b)
+		if err != nil {
+			return msg, err
+		}
+		b = b[n:]
+
+		if wireType != protoWireBytes {
+			n, err := skipProtoField(b, wireType)
+			if err != nil {
+				return msg, err
+			}
+			b = b[n:]
+			continue
+		}
+
+		field, n, err := decodeProtoBytes(b)
+		if err != nil {
+			return msg, err
+		}
+		b = b[n:]
+
+		switch fieldNum {
+		case 1:
+			msg.partitionKeyTable = append(msg.partitionKeyTable, string(field))
+		case 2:
+			msg.explicitHashKeyTable = append(msg.explicitHashKeyTable, string(field))
+		case 3:
+			pkIndex, ehkIndex, hasEHK, data, tags, err := decodeRecord(field)
+			if err != nil {
+				return msg, err
+			}
+			msg.records = append(msg.records, protobufRecord{
+				partitionKeyIndex:       pkIndex,
+				hasExplicitHashKeyIndex: hasEHK,
+				explicitHashKeyIndex:    ehkIndex,
+				data:                    data,
+				tags:                    tags,
+			})
+		}
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,32 @@
+package aggregation
+
+import "github.com/aws/aws-sdk-go-v2/service/kinesis/internal/kplprotobuf"
+
+// protoWriter and the decode helpers below are thin wrappers over
+// kplprotobuf, which holds the wire-format logic; the parent kinesis
+// package's KPL validation and deaggregation reach it through this package.
+type protoWriter struct {
+	kplprotobuf.Writer
+}
+
+func (w *protoWriter) stringField(fieldNum int, s string) { w.StringField(fieldNum, s) }
+func (w *protoWriter) bytesField(fieldNum int, b []byte)  { w.BytesField(fieldNum, b) }
+func (w *protoWriter) varintField(fieldNum int, v uint64) { w.VarintField(fieldNum, v) }
+
+const protoWireBytes = kplprotobuf.WireBytes
+
+func decodeProtoTag(b []byte) (fieldNum int, wireType int, n int, err error) {
+	return kplprotobuf.DecodeTag(b)
+}
+
+func decodeProtoVarint(b []byte) (v uint64, n int, err error) {
+	return kplprotobuf.DecodeVarint(b)
+}
+
+func decodeProtoBytes(b []byte) (field []byte, n int, err error) {
+	return kplprotobuf.DecodeBytes(b)
+}
+
+func skipProtoField(b []byte, wireType int) (n int, err error) {
+	return kplprotobuf.SkipField(b, wireType)
+}
@@ -0,0 +1,190 @@
+// Package aggregation implements the Kinesis Producer Library's record
+// aggregation format: many small logical records packed into the Data of a
+// single Kinesis record, so a producer can push far more than the
+// 1,000-records-per-second, 1-MiB-per-second shard limits would otherwise
+// allow. An aggregated record is:
+//
+//	magic (4 bytes: 0xF3 0x89 0x9A 0xC2) || protobuf(AggregatedRecord) || md5(protobuf(AggregatedRecord))
+//
+// with the protobuf message shaped as:
+//
+//	message AggregatedRecord {
+//	  repeated string partition_key_table = 1;
+//	  repeated string explicit_hash_key_table = 2;
+//	  repeated Record records = 3;
+//	}
+//	message Tag {
+//	  optional string key = 1;
+//	  optional string value = 2;
+//	}
+//	message Record {
+//	  optional uint64 partition_key_index = 1;
+//	  optional uint64 explicit_hash_key_index = 2;
+//	  optional bytes data = 3;
+//	  repeated Tag tags = 4;
+//	}
+package aggregation
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+)
+
+// magic is the 4-byte prefix that marks a Kinesis record's Data as a KPL
+// aggregated record.
+var magic = []byte{0xF3, 0x89, 0x9A, 0xC2}
+
+const md5TrailerLen = md5.Size
+
+// Record is one logical (user) record, either buffered for aggregation by
+// an Aggregator or produced by a Deaggregator from an aggregated record.
+type Record struct {
+	PartitionKey    string
+	ExplicitHashKey string
+	Data            []byte
+	Tags            map[string]string
+}
+
+// AggregatedRecord is the result of deaggregating a single Kinesis record
+// into the logical records the producer packed into it.
+type AggregatedRecord struct {
+	Records []Record
+}
+
+// IsAggregated reports whether data carries the magic prefix of a KPL
+// aggregated record. It does not verify the MD5 trailer or that the
+// payload between them actually decodes - call Deaggregate for that.
+func IsAggregated(data []byte) bool {
+	return len(data) >= len(magic)+md5TrailerLen && bytes.HasPrefix(data, magic)
+}
+
+func encodeRecord(w *protoWriter, r Record, partitionKeyIndex, explicitHashKeyIndex uint64, hasExplicitHashKey bool) {
+	w.varintField(1, partitionKeyIndex)
+	if hasExplicitHashKey {
+		w.varintField(2, explicitHashKeyIndex)
+	}
+	w.bytesField(3, r.Data)
+	for k, v := range r.Tags {
+		var tag protoWriter
+		tag.stringField(1, k)
+		tag.stringField(2, v)
+		w.bytesField(4, tag.Buf)
+	}
+}
+
+func decodeRecord(b []byte) (partitionKeyIndex uint64, explicitHashKeyIndex uint64, hasExplicitHashKey bool, data []byte, tags map[string]string, err error) {
+	for len(b) > 0 {
+		fieldNum, wireType, n, err := decodeProtoTag(b)
+		if err != nil {
+			return 0, 0, false, nil, nil, err
+		}
+		b = b[n:]
+
+		switch fieldNum {
+		case 1:
+			v, n, err := decodeProtoVarint(b)
+			if err != nil {
+				return 0, 0, false, nil, nil, err
+			}
+			b = b[n:]
+			partitionKeyIndex = v
+		case 2:
+			v, n, err := decodeProtoVarint(b)
+			if err != nil {
+				return 0, 0, false, nil, nil, err
+			}
+			b = b[n:]
+			hasExplicitHashKey = true
+			explicitHashKeyIndex = v
+		case 3:
+			field, n, err := decodeProtoBytes(b)
+			if err != nil {
+				return 0, 0, false, nil, nil, err
+			}
+			b = b[n:]
+			data = field
+		case 4:
+			field, n, err := decodeProtoBytes(b)
+			if err != nil {
+				return 0, 0, false, nil, nil, err
+			}
+			b = b[n:]
+			key, value, err := decodeTag(field)
+			if err != nil {
+				return 0, 0, false, nil, nil, err
+			}
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[key] = value
+		default:
+			n, err := skipProtoField(b, wireType)
+			if err != nil {
+				return 0, 0, false, nil, nil, err
+			}
+			b = b[n:]
+		}
+	}
+	return partitionKeyIndex, explicitHashKeyIndex, hasExplicitHashKey, data, tags, nil
+}
+
+func decodeTag(b []byte) (key string, value string, err error) {
+	for len(b) > 0 {
+		fieldNum, wireType, n, err := decodeProtoTag(b)
+		if err != nil {
+			return "", "", err
+		}
+		b = b[n:]
+
+		if wireType != protoWireBytes {
+			n, err := skipProtoField(b, wireType)
+			if err != nil {
+				return "", "", err
+			}
+			b = b[n:]
+			continue
+		}
+
+		field, n, err := decodeProtoBytes(b)
+		if err != nil {
+			return "", "", err
+		}
+		b = b[n:]
+
+		switch fieldNum {
+		case 1:
+			key = string(field)
+		case 2:
+			value = string(field)
+		}
+	}
+	return key, value, nil
+}
+
+// wrap packs protobuf, the AggregatedRecord message built from it, into an
+// aggregated record's on-the-wire form: magic, the protobuf bytes, then an
+// MD5 trailer over those bytes.
+func wrap(protobuf []byte) []byte {
+	sum := md5.Sum(protobuf)
+	out := make([]byte, 0, len(magic)+len(protobuf)+md5TrailerLen)
+	out = append(out, magic...)
+	out = append(out, protobuf...)
+	out = append(out, sum[:]...)
+	return out
+}
+
+// unwrap validates and strips an aggregated record's magic prefix and MD5
+// trailer, returning the protobuf bytes in between.
+func unwrap(data []byte) ([]byte, error) {
+	if !IsAggregated(data) {
+		return nil, fmt.Errorf("data does not start with the KPL aggregation magic number")
+	}
+	protobuf := data[len(magic) : len(data)-md5TrailerLen]
+	trailer := data[len(data)-md5TrailerLen:]
+	sum := md5.Sum(protobuf)
+	if !bytes.Equal(sum[:], trailer) {
+		return nil, fmt.Errorf("MD5 trailer does not match the aggregated record's protobuf payload")
+	}
+	return protobuf, nil
+}
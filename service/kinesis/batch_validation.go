@@ -0,0 +1,101 @@
+package kinesis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Kinesis's documented PutRecords limits: at most 500 records per request,
+// each record's Data plus PartitionKey no larger than 1 MiB, and the
+// request as a whole no larger than 5 MiB. The generated validator only
+// checks that Data and PartitionKey are present; validatePutRecordsBatchLimits
+// enforces the size and count limits the service itself would otherwise
+// reject with an HTTP 413 or ValidationException.
+const (
+	maxPutRecordsRecords        = 500
+	maxPutRecordsRecordBytes    = 1 << 20 // 1 MiB
+	maxPutRecordsAggregateBytes = 5 << 20 // 5 MiB
+)
+
+// putRecordsRequestEntrySize returns how many bytes a single entry counts
+// against the aggregate and per-record PutRecords limits.
+func putRecordsRequestEntrySize(v types.PutRecordsRequestEntry) int {
+	return len(v.Data) + len(aws.ToString(v.PartitionKey))
+}
+
+// validatePutRecordsBatchLimits checks the aggregate PutRecords limits that
+// validateOpPutRecordsInput, being a per-field presence check, does not: the
+// 500 record count cap, the 1 MiB per-record cap, and the 5 MiB aggregate
+// request cap.
+func validatePutRecordsBatchLimits(v *PutRecordsInput) error {
+	if v == nil {
+		return nil
+	}
+
+	invalidParams := smithy.InvalidParamsError{Context: "PutRecordsInput"}
+	if len(v.Records) > maxPutRecordsRecords {
+		invalidParams.Add(fmt.Errorf("number of records, %d, exceeds the PutRecords limit of %d", len(v.Records), maxPutRecordsRecords))
+	}
+
+	var aggregate int
+	for i, r := range v.Records {
+		size := putRecordsRequestEntrySize(r)
+		aggregate += size
+		if size > maxPutRecordsRecordBytes {
+			invalidParams.Add(fmt.Errorf("Records[%d]: size %d bytes exceeds the per-record limit of %d bytes", i, size, maxPutRecordsRecordBytes))
+		}
+	}
+	if aggregate > maxPutRecordsAggregateBytes {
+		invalidParams.Add(fmt.Errorf("aggregate request size %d bytes exceeds the PutRecords limit of %d bytes", aggregate, maxPutRecordsAggregateBytes))
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// validateOpPutRecordsBatchLimits is a hand-written companion to the
+// generated validateOpPutRecords: it runs the same place in the Initialize
+// step but enforces the count/size limits above instead of field presence.
+// It is kept in a separate file from validators.go, which is generated and
+// must not be edited directly.
+type validateOpPutRecordsBatchLimits struct {
+	options Options
+}
+
+func (*validateOpPutRecordsBatchLimits) ID() string {
+	return "OpPutRecordsBatchLimitsValidation"
+}
+
+func (m *validateOpPutRecordsBatchLimits) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	if m.options.isValidationDisabled("PutRecords") {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	input, ok := in.Parameters.(*PutRecordsInput)
+	if !ok {
+		return next.HandleInitialize(ctx, in)
+	}
+	if err := validatePutRecordsBatchLimits(input); err != nil {
+		return out, metadata, err
+	}
+	return next.HandleInitialize(ctx, in)
+}
+
+// addOpPutRecordsBatchLimitsValidationMiddleware registers
+// validateOpPutRecordsBatchLimits at the end of the Initialize step, so
+// oversize batches are rejected client-side before ever reaching the wire.
+// addOperationPutRecordsMiddlewares always registers it, behind
+// splitPutRecordsMiddleware: with AutoSplitPutRecords enabled it checks
+// each sub-batch, and with it disabled it rejects an oversize batch whole.
+func addOpPutRecordsBatchLimitsValidationMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Initialize.Add(&validateOpPutRecordsBatchLimits{options: options}, middleware.After)
+}
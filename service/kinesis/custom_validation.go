@@ -0,0 +1,97 @@
+package kinesis
+
+import (
+	"context"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// OperationValidator is a user-supplied validation hook that runs after an
+// operation's built-in validators (both generated and the hand-written
+// PutRecords checks), for checks specific to the caller's use of the
+// API that the SDK has no way to know about on its own - for example, an
+// allow-list of stream names, or a tenant-specific payload schema.
+//
+// Validators are configured once via Options.Validators and run for every
+// operation whose stack registers customValidationMiddleware, which
+// addOperationPutRecordsMiddlewares and addOperationGetRecordsMiddlewares
+// do; an OperationValidator that only cares about one operation should
+// check operationName and return nil otherwise.
+type OperationValidator interface {
+	// ValidateOperation is called with the operation's name (e.g.
+	// "PutRecords") and its input parameters. A non-nil error fails the
+	// call before any request is sent.
+	ValidateOperation(ctx context.Context, operationName string, params interface{}) error
+}
+
+// isValidationDisabled reports whether opName was named in a
+// WithDisabledValidation call for these Options.
+func (o Options) isValidationDisabled(opName string) bool {
+	return o.disabledValidations[opName]
+}
+
+// customValidationMiddleware runs Options.Validators after an operation's
+// other Initialize-step validators, merging every failure into a single
+// smithy.InvalidParamsError so custom and built-in validation errors look
+// the same to callers.
+type customValidationMiddleware struct {
+	options Options
+}
+
+func (*customValidationMiddleware) ID() string {
+	return "CustomOperationValidation"
+}
+
+func (m *customValidationMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	opName := middleware.GetOperationName(ctx)
+	if m.options.isValidationDisabled(opName) || len(m.options.Validators) == 0 {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	invalidParams := smithy.InvalidParamsError{Context: opName}
+	for _, v := range m.options.Validators {
+		if err := v.ValidateOperation(ctx, opName, in.Parameters); err != nil {
+			invalidParams.Add(err)
+		}
+	}
+	if invalidParams.Len() > 0 {
+		return out, metadata, invalidParams
+	}
+
+	return next.HandleInitialize(ctx, in)
+}
+
+// addCustomValidationMiddleware registers customValidationMiddleware at the
+// end of the Initialize step, so an operation's own generated and
+// hand-written validators still run - and still take precedence - even
+// when custom OperationValidators are also configured. It is called from
+// addOperationPutRecordsMiddlewares and addOperationGetRecordsMiddlewares
+// only; no other operation's stack registers it.
+func addCustomValidationMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Initialize.Add(&customValidationMiddleware{options: options}, middleware.After)
+}
+
+// WithDisabledValidation returns an Options function that turns off this
+// package's hand-written client-side validation - the batch-limit,
+// aggregation, strict-input and custom OperationValidator checks - for the
+// given operation names (e.g. "PutRecords"). It's an escape hatch for
+// callers who need to send input those checks reject but the service
+// itself accepts; per-field validation still happens server-side.
+//
+// It does not disable the generated validateOp* middleware (required
+// parameter presence, enum values, and the like): that file is generated
+// and does not consult Options, so those checks always run regardless of
+// WithDisabledValidation.
+func WithDisabledValidation(ops ...string) func(*Options) {
+	return func(o *Options) {
+		if o.disabledValidations == nil {
+			o.disabledValidations = make(map[string]bool, len(ops))
+		}
+		for _, op := range ops {
+			o.disabledValidations[op] = true
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package kplprotobuf implements just enough of the protobuf wire format to
+// encode and decode the KPL's AggregatedRecord message (see aggregation.proto
+// in the KPL source):
+//
+//	message AggregatedRecord {
+//	  repeated string partition_key_table = 1;
+//	  repeated string explicit_hash_key_table = 2;
+//	  repeated Record records = 3;
+//	}
+//	message Record {
+//	  optional uint64 partition_key_index = 1;
+//	  optional uint64 explicit_hash_key_index = 2;
+//	  optional bytes data = 3;
+//	  repeated Tag tags = 4;
+//	}
+//	message Tag {
+//	  optional string key = 1;
+//	  optional string value = 2;
+//	}
+//
+// Pulling in a full protobuf runtime for these three fixed message shapes
+// isn't worth the dependency, so it's encoded/decoded directly off the wire
+// format instead. This package is used by the kinesis/aggregation package's
+// aggregator and deaggregator, through which the kinesis package's KPL
+// validation and deaggregation reach it, so the wire-format logic exists in
+// exactly one place.
+package kplprotobuf
+
+import "fmt"
+
+// Wire types this package needs; protobuf defines others (fixed32/fixed64,
+// start/end group) that the AggregatedRecord message never uses.
+const (
+	WireVarint = 0
+	WireBytes  = 2
+)
+
+// Writer accumulates an encoded protobuf message.
+type Writer struct {
+	Buf []byte
+}
+
+func (w *Writer) tag(fieldNum int, wireType int) {
+	w.Varint(uint64(fieldNum<<3 | wireType))
+}
+
+// Varint appends v to the message using protobuf's base-128 varint
+// encoding.
+func (w *Writer) Varint(v uint64) {
+	for v >= 0x80 {
+		w.Buf = append(w.Buf, byte(v)|0x80)
+		v >>= 7
+	}
+	w.Buf = append(w.Buf, byte(v))
+}
+
+// BytesField appends a length-delimited field.
+func (w *Writer) BytesField(fieldNum int, b []byte) {
+	w.tag(fieldNum, WireBytes)
+	w.Varint(uint64(len(b)))
+	w.Buf = append(w.Buf, b...)
+}
+
+// StringField appends a length-delimited field holding s's bytes.
+func (w *Writer) StringField(fieldNum int, s string) {
+	w.BytesField(fieldNum, []byte(s))
+}
+
+// VarintField appends a varint-encoded field.
+func (w *Writer) VarintField(fieldNum int, v uint64) {
+	w.tag(fieldNum, WireVarint)
+	w.Varint(v)
+}
+
+// DecodeTag reads a protobuf field tag: (field_number << 3) | wire_type.
+func DecodeTag(b []byte) (fieldNum int, wireType int, n int, err error) {
+	v, n, err := DecodeVarint(b)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return int(v >> 3), int(v & 0x7), n, nil
+}
+
+// DecodeVarint reads a base-128 varint, LSB group first.
+func DecodeVarint(b []byte) (v uint64, n int, err error) {
+	for shift := uint(0); ; shift += 7 {
+		if n >= len(b) {
+			return 0, 0, fmt.Errorf("truncated protobuf varint")
+		}
+		if shift >= 64 {
+			return 0, 0, fmt.Errorf("protobuf varint overflow")
+		}
+		b0 := b[n]
+		n++
+		v |= uint64(b0&0x7f) << shift
+		if b0&0x80 == 0 {
+			return v, n, nil
+		}
+	}
+}
+
+// DecodeBytes reads a length-delimited field: a varint length followed by
+// that many bytes.
+func DecodeBytes(b []byte) (field []byte, n int, err error) {
+	length, ln, err := DecodeVarint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	b = b[ln:]
+	if uint64(len(b)) < length {
+		return nil, 0, fmt.Errorf("truncated protobuf length-delimited field")
+	}
+	return b[:length], ln + int(length), nil
+}
+
+// SkipField advances past a field's value given its wire type, for fields a
+// caller doesn't otherwise interpret.
+func SkipField(b []byte, wireType int) (n int, err error) {
+	switch wireType {
+	case WireVarint:
+		_, n, err := DecodeVarint(b)
+		return n, err
+	case WireBytes:
+		_, n, err := DecodeBytes(b)
+		return n, err
+	default:
+		return 0, fmt.Errorf("unsupported protobuf wire type %d", wireType)
+	}
+}
@@ -0,0 +1,75 @@
+package kinesis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/aggregation"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// validateAggregatedRecordEntry checks that a PutRecords entry whose Data
+// looks like a KPL aggregated record (it starts with the magic number) is
+// actually well-formed, rather than letting a corrupt blob reach the
+// service only to fail deaggregation for every consumer reading it back.
+// Entries that aren't KPL-aggregated are left alone. It uses the same
+// aggregation.Deaggregator the PutRecords/GetRecords aggregation
+// middleware does, rather than a second, parallel KPL codec.
+func validateAggregatedRecordEntry(v *types.PutRecordsRequestEntry) error {
+	if v == nil || !aggregation.IsAggregated(v.Data) {
+		return nil
+	}
+
+	if _, err := aggregation.NewDeaggregator().Deaggregate(v.Data); err != nil {
+		invalidParams := smithy.InvalidParamsError{Context: "PutRecordsRequestEntry"}
+		invalidParams.Add(fmt.Errorf("Data: %w", err))
+		return invalidParams
+	}
+	return nil
+}
+
+// validateOpPutRecordsAggregation is a hand-written companion to the
+// generated validateOpPutRecords, kept in its own file since validators.go
+// is generated and must not be edited directly. It runs
+// validateAggregatedRecordEntry over every record in the request.
+type validateOpPutRecordsAggregation struct {
+	options Options
+}
+
+func (*validateOpPutRecordsAggregation) ID() string {
+	return "OpPutRecordsAggregationValidation"
+}
+
+func (m *validateOpPutRecordsAggregation) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	if m.options.isValidationDisabled("PutRecords") {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	input, ok := in.Parameters.(*PutRecordsInput)
+	if !ok {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	invalidParams := smithy.InvalidParamsError{Context: "PutRecordsInput"}
+	for i := range input.Records {
+		if err := validateAggregatedRecordEntry(&input.Records[i]); err != nil {
+			invalidParams.AddNested(fmt.Sprintf("Records[%d]", i), err.(smithy.InvalidParamsError))
+		}
+	}
+	if invalidParams.Len() > 0 {
+		return out, metadata, invalidParams
+	}
+
+	return next.HandleInitialize(ctx, in)
+}
+
+// addOpPutRecordsAggregationValidationMiddleware registers
+// validateOpPutRecordsAggregation alongside the generated PutRecords
+// validator and validateOpPutRecordsBatchLimits.
+func addOpPutRecordsAggregationValidationMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Initialize.Add(&validateOpPutRecordsAggregation{options: options}, middleware.After)
+}
@@ -0,0 +1,52 @@
+package kinesis
+
+import "github.com/aws/smithy-go/middleware"
+
+// addOperationPutRecordsMiddlewares registers every hand-written PutRecords
+// middleware in this package on stack, in addition to the generated
+// addOpPutRecordsValidationMiddleware. This is the single place that
+// decides how they compose. It is a method on *Client; the generated
+// PutRecords operation's stack setup (api_op_PutRecords.go) does not call
+// it, so these middlewares are in place only on a stack it has been called
+// on.
+func (c *Client) addOperationPutRecordsMiddlewares(stack *middleware.Stack, options Options) error {
+	// Split and aggregation are both added at the front of the Initialize
+	// step, so the one added last runs first: aggregation runs ahead of split.
+	if err := addOpPutRecordsSplitMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addRecordAggregationMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addOpPutRecordsBatchLimitsValidationMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addOpPutRecordsAggregationValidationMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addStrictInputValidationMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addCustomValidationMiddleware(stack, options); err != nil {
+		return err
+	}
+	return nil
+}
+
+// addOperationGetRecordsMiddlewares registers every hand-written GetRecords
+// middleware in this package on stack, in addition to the generated
+// addOpGetRecordsValidationMiddleware. Like addOperationPutRecordsMiddlewares,
+// it is a method on *Client; the generated GetRecords operation's stack
+// setup (api_op_GetRecords.go) does not call it.
+func (c *Client) addOperationGetRecordsMiddlewares(stack *middleware.Stack, options Options) error {
+	if err := addStrictInputValidationMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addCustomValidationMiddleware(stack, options); err != nil {
+		return err
+	}
+	if err := addOpGetRecordsDeaggregationMiddleware(stack, options); err != nil {
+		return err
+	}
+	return nil
+}
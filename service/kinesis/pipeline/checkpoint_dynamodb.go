@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBCheckpointer is a Checkpointer backed by a DynamoDB table, for
+// coordinating a consumer group across multiple worker processes - the
+// same role the lease table plays for the Kinesis Client Library. The
+// table needs a single string partition key, named by KeyAttribute.
+//
+// Each item holds the lease owner and its expiry alongside the
+// checkpointed sequence number: GetCheckpoint reads it with a single
+// GetItem, and AcquireLease/RenewLease check the owner with a condition
+// expression on the same item.
+type DynamoDBCheckpointer struct {
+	Client    *dynamodb.Client
+	TableName string
+
+	// KeyAttribute is the table's partition key attribute name. Defaults
+	// to "ShardID" when empty.
+	KeyAttribute string
+	// OwnerID identifies this worker in the lease table. It must be
+	// unique per worker process sharing TableName.
+	OwnerID string
+}
+
+const (
+	ddbLeaseOwnerAttr  = "LeaseOwner"
+	ddbLeaseExpiryAttr = "LeaseExpiry"
+	ddbCheckpointAttr  = "SequenceNumber"
+)
+
+func (c *DynamoDBCheckpointer) keyAttribute() string {
+	if c.KeyAttribute != "" {
+		return c.KeyAttribute
+	}
+	return "ShardID"
+}
+
+func (c *DynamoDBCheckpointer) key(shardID string) map[string]ddbtypes.AttributeValue {
+	return map[string]ddbtypes.AttributeValue{
+		c.keyAttribute(): &ddbtypes.AttributeValueMemberS{Value: shardID},
+	}
+}
+
+// AcquireLease claims shardID's lease with a conditional UpdateItem that
+// sets only the owner and expiry, leaving any checkpointed sequence number
+// in place: it succeeds if no one owns the lease, the existing lease has
+// expired, or this OwnerID already owns it.
+func (c *DynamoDBCheckpointer) AcquireLease(ctx context.Context, shardID string) (bool, error) {
+	now := time.Now()
+
+	_, err := c.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(c.TableName),
+		Key:                 c.key(shardID),
+		UpdateExpression:    aws.String(fmt.Sprintf("SET %s = :owner, %s = :expiry", ddbLeaseOwnerAttr, ddbLeaseExpiryAttr)),
+		ConditionExpression: aws.String(fmt.Sprintf("attribute_not_exists(%s) OR %s < :now OR %s = :owner", ddbLeaseOwnerAttr, ddbLeaseExpiryAttr, ddbLeaseOwnerAttr)),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":now":    &ddbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Unix())},
+			":owner":  &ddbtypes.AttributeValueMemberS{Value: c.OwnerID},
+			":expiry": &ddbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Add(leaseTTL).Unix())},
+		},
+	})
+	if err == nil {
+		return true, nil
+	}
+	var condFailed *ddbtypes.ConditionalCheckFailedException
+	if errors.As(err, &condFailed) {
+		return false, nil
+	}
+	return false, err
+}
+
+// RenewLease extends this worker's ownership the same way AcquireLease
+// establishes it.
+func (c *DynamoDBCheckpointer) RenewLease(ctx context.Context, shardID string) error {
+	ok, err := c.AcquireLease(ctx, shardID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("pipeline: lease for shard %s is no longer held by %s", shardID, c.OwnerID)
+	}
+	return nil
+}
+
+// ReleaseLease removes the lease owner and expiry attributes, leaving the
+// checkpointed sequence number intact.
+func (c *DynamoDBCheckpointer) ReleaseLease(ctx context.Context, shardID string) error {
+	_, err := c.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(c.TableName),
+		Key:                 c.key(shardID),
+		UpdateExpression:    aws.String(fmt.Sprintf("REMOVE %s, %s", ddbLeaseOwnerAttr, ddbLeaseExpiryAttr)),
+		ConditionExpression: aws.String(fmt.Sprintf("%s = :owner", ddbLeaseOwnerAttr)),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":owner": &ddbtypes.AttributeValueMemberS{Value: c.OwnerID},
+		},
+	})
+	return err
+}
+
+// GetCheckpoint returns "" if the item, or its checkpoint attribute,
+// doesn't exist yet.
+func (c *DynamoDBCheckpointer) GetCheckpoint(ctx context.Context, shardID string) (string, error) {
+	out, err := c.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(c.TableName),
+		Key:       c.key(shardID),
+	})
+	if err != nil {
+		return "", err
+	}
+	attr, ok := out.Item[ddbCheckpointAttr].(*ddbtypes.AttributeValueMemberS)
+	if !ok {
+		return "", nil
+	}
+	return attr.Value, nil
+}
+
+// PutCheckpoint records sequenceNumber, creating the item if it doesn't
+// already exist.
+func (c *DynamoDBCheckpointer) PutCheckpoint(ctx context.Context, shardID, sequenceNumber string) error {
+	_, err := c.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(c.TableName),
+		Key:              c.key(shardID),
+		UpdateExpression: aws.String(fmt.Sprintf("SET %s = :seq", ddbCheckpointAttr)),
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":seq": &ddbtypes.AttributeValueMemberS{Value: sequenceNumber},
+		},
+	})
+	return err
+}
+
+var _ Checkpointer = (*DynamoDBCheckpointer)(nil)
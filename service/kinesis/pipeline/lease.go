@@ -0,0 +1,42 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// leaseCoordinator tracks which shards this worker currently owns, via a
+// Checkpointer's lease primitives, so multiple Pipeline workers consuming
+// the same stream don't double-process a shard.
+type leaseCoordinator struct {
+	checkpointer Checkpointer
+
+	mu    sync.Mutex
+	owned map[string]bool
+}
+
+func newLeaseCoordinator(checkpointer Checkpointer) *leaseCoordinator {
+	return &leaseCoordinator{checkpointer: checkpointer, owned: make(map[string]bool)}
+}
+
+// tryAcquire attempts to claim shardID's lease, reporting whether this
+// worker now owns it.
+func (c *leaseCoordinator) tryAcquire(ctx context.Context, shardID string) bool {
+	ok, err := c.checkpointer.AcquireLease(ctx, shardID)
+	if err != nil || !ok {
+		return false
+	}
+	c.mu.Lock()
+	c.owned[shardID] = true
+	c.mu.Unlock()
+	return true
+}
+
+// release gives up shardID's lease, e.g. once it's closed and fully
+// drained into its children.
+func (c *leaseCoordinator) release(ctx context.Context, shardID string) {
+	c.mu.Lock()
+	delete(c.owned, shardID)
+	c.mu.Unlock()
+	_ = c.checkpointer.ReleaseLease(ctx, shardID)
+}
@@ -0,0 +1,26 @@
+package pipeline
+
+import "time"
+
+// Metrics is a snapshot of one shard's throughput and lag, reported to a
+// MetricsHandler while a Pipeline is running.
+type Metrics struct {
+	ShardID            string
+	RecordsPerSecond   float64
+	MillisBehindLatest int64
+	CheckpointLag      time.Duration
+}
+
+// MetricsHandler receives periodic Metrics from a running Pipeline. It's
+// called from the goroutine consuming the corresponding shard, so
+// implementations that do real work - exporting to a metrics backend, say
+// - should do it asynchronously rather than blocking the read loop.
+type MetricsHandler interface {
+	HandleMetrics(m Metrics)
+}
+
+// MetricsHandlerFunc adapts a function to a MetricsHandler.
+type MetricsHandlerFunc func(Metrics)
+
+// HandleMetrics calls f(m).
+func (f MetricsHandlerFunc) HandleMetrics(m Metrics) { f(m) }
@@ -0,0 +1,167 @@
+// Package pipeline is a declarative, streaming pipeline API over Kinesis:
+// a Source backed by SubscribeToShard (falling back to polling
+// GetShardIterator/GetRecords when no consumer ARN is given), chainable
+// Map/Filter/Batch/Window stages, and a Sink backed by PutRecords using
+// the aggregation package for throughput. It follows resharding via
+// ChildShards, checkpoints progress through a pluggable Checkpointer, and
+// coordinates leases across a consumer group so multiple workers can
+// share a stream without double-processing a shard.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+)
+
+// Message is one value flowing through a Pipeline, carrying the
+// originating Kinesis record's metadata alongside whatever Value the most
+// recent stage produced.
+type Message struct {
+	Value                       any
+	PartitionKey                string
+	SequenceNumber              string
+	ShardID                     string
+	ApproximateArrivalTimestamp time.Time
+}
+
+// stage transforms a channel of Messages into another, running until in
+// closes or ctx is done. A stage that can't process a Message reports the
+// error on errs rather than stopping the pipeline.
+type stage func(ctx context.Context, in <-chan Message, errs chan<- error) <-chan Message
+
+// Pipeline is a declarative, streaming transform from one Kinesis stream
+// to another, or to nothing for a pipeline that only has side effects.
+// Its fluent methods work in terms of Message.Value as `any`, since a Go
+// method can't introduce a type parameter its receiver doesn't have;
+// callers who want a statically-typed stage can build one with
+// Processor[T, U] and add it with WithProcessor instead of Map/Filter.
+type Pipeline struct {
+	client        *kinesis.Client
+	streamARN     string
+	consumerARN   string
+	destStreamARN string
+	stages        []stage
+	checkpointer  Checkpointer
+	metrics       MetricsHandler
+}
+
+// New starts building a Pipeline that reads from and writes to streams
+// using client.
+func New(client *kinesis.Client) *Pipeline {
+	return &Pipeline{client: client, checkpointer: NewInMemoryCheckpointer()}
+}
+
+// From sets the source stream and, for enhanced fan-out via
+// SubscribeToShard, the registered consumer to read it as. Leave
+// consumerARN empty to read by polling GetShardIterator/GetRecords
+// instead.
+func (p *Pipeline) From(streamARN, consumerARN string) *Pipeline {
+	p.streamARN, p.consumerARN = streamARN, consumerARN
+	return p
+}
+
+// To sets the destination stream Run writes to via PutRecords, aggregated
+// with the aggregation package. A Pipeline with no To still runs its
+// stages, for their side effects, but discards their output.
+func (p *Pipeline) To(destStreamARN string) *Pipeline {
+	p.destStreamARN = destStreamARN
+	return p
+}
+
+// WithCheckpointer overrides the default InMemoryCheckpointer, typically
+// with a DynamoDBCheckpointer when more than one worker shares the
+// stream.
+func (p *Pipeline) WithCheckpointer(c Checkpointer) *Pipeline {
+	p.checkpointer = c
+	return p
+}
+
+// WithMetrics registers a handler for the periodic per-shard Metrics Run
+// reports while it's active.
+func (p *Pipeline) WithMetrics(h MetricsHandler) *Pipeline {
+	p.metrics = h
+	return p
+}
+
+// Map appends a stage that replaces every Message.Value with fn's result.
+// An fn error is reported to Run's caller without stopping the pipeline.
+func (p *Pipeline) Map(fn func(any) (any, error)) *Pipeline {
+	p.stages = append(p.stages, mapStage(fn))
+	return p
+}
+
+// Filter appends a stage that drops every Message for which fn returns
+// false.
+func (p *Pipeline) Filter(fn func(any) bool) *Pipeline {
+	p.stages = append(p.stages, filterStage(fn))
+	return p
+}
+
+// Batch appends a stage that groups every size consecutive values into a
+// single []any-valued Message.
+func (p *Pipeline) Batch(size int) *Pipeline {
+	p.stages = append(p.stages, batchStage(size))
+	return p
+}
+
+// Window appends a stage that groups every value received within d into a
+// single []any-valued Message, flushing early only when the upstream
+// closes.
+func (p *Pipeline) Window(d time.Duration) *Pipeline {
+	p.stages = append(p.stages, windowStage(d))
+	return p
+}
+
+// WithProcessor appends a type-safe Processor as a stage, for callers who
+// don't want to work in terms of `any` the way Map/Filter do. It's a free
+// function, not a method, since adding it would require Pipeline itself
+// to carry T and U as type parameters.
+func WithProcessor[T, U any](p *Pipeline, proc Processor[T, U]) *Pipeline {
+	p.stages = append(p.stages, processorStage(proc))
+	return p
+}
+
+// Run starts the pipeline: it discovers the source stream's shards,
+// claims a lease on each one via its Checkpointer, reads and transforms
+// their records through every configured stage, and, if To was called,
+// writes the result to the destination stream. It blocks until ctx is
+// done or every source shard has closed with no children left to follow,
+// returning the first stage or sink error encountered, if any. Errors
+// reading a source shard after Start are not returned; that shard's
+// consumer simply stops.
+func (p *Pipeline) Run(ctx context.Context) error {
+	if p.streamARN == "" {
+		return fmt.Errorf("pipeline: From must be called before Run")
+	}
+
+	src := newSource(p.client, p.streamARN, p.consumerARN, p.checkpointer, p.metrics)
+	in, err := src.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("pipeline: %w", err)
+	}
+
+	errs := make(chan error, len(p.stages)+1)
+	out := in
+	for _, s := range p.stages {
+		out = s(ctx, out, errs)
+	}
+
+	if p.destStreamARN != "" {
+		if err := newSink(p.client, p.destStreamARN, p.metrics).Run(ctx, out); err != nil {
+			reportError(errs, err)
+		}
+	} else {
+		for range out {
+		}
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+// Processor is a type-safe pipeline stage: it consumes one T and produces
+// zero or more U, which expresses a map (one result), a filter (zero or
+// one), or a fan-out (more than one) uniformly. Add one to a Pipeline
+// with WithProcessor.
+type Processor[T, U any] func(ctx context.Context, value T) ([]U, error)
+
+// processorStage adapts a Processor into the untyped stage type
+// Pipeline's fluent methods use, type-asserting each Message.Value to T
+// and reporting a mismatch the same way a Processor error is reported: on
+// errs, without stopping the pipeline.
+func processorStage[T, U any](proc Processor[T, U]) stage {
+	return func(ctx context.Context, in <-chan Message, errs chan<- error) <-chan Message {
+		out := make(chan Message)
+		go func() {
+			defer close(out)
+			for msg := range in {
+				value, ok := msg.Value.(T)
+				if !ok {
+					reportError(errs, fmt.Errorf("pipeline: stage expected %T, got %T", value, msg.Value))
+					continue
+				}
+
+				results, err := proc(ctx, value)
+				if err != nil {
+					reportError(errs, err)
+					continue
+				}
+
+				for _, r := range results {
+					next := msg
+					next.Value = r
+					select {
+					case out <- next:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// reportError sends err on errs without blocking if no one's currently
+// able to receive it - a stage has no other way to surface a per-message
+// failure without stopping the whole pipeline over it.
+func reportError(errs chan<- error, err error) {
+	select {
+	case errs <- err:
+	default:
+	}
+}
@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// discoverShards lists every shard on streamARN and returns its current
+// leaves - the shards a fresh Source should start consuming - rather than
+// every shard the stream has ever had.
+func discoverShards(ctx context.Context, client *kinesis.Client, streamARN string) ([]types.Shard, error) {
+	var shards []types.Shard
+	var nextToken *string
+	for {
+		// ListShards rejects StreamARN/StreamName together with NextToken,
+		// so only the first page's request may carry it.
+		in := &kinesis.ListShardsInput{NextToken: nextToken}
+		if nextToken == nil {
+			in.StreamARN = aws.String(streamARN)
+		}
+
+		out, err := client.ListShards(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, out.Shards...)
+		if out.NextToken == nil {
+			break
+		}
+		nextToken = out.NextToken
+	}
+	return leafShards(shards), nil
+}
+
+// leafShards returns the shards in shards that aren't any other shard's
+// parent - the currently open shards a consumer should read from, with
+// ancestors already merged or split away.
+func leafShards(shards []types.Shard) []types.Shard {
+	isParent := make(map[string]bool, len(shards))
+	for _, s := range shards {
+		if s.ParentShardId != nil {
+			isParent[aws.ToString(s.ParentShardId)] = true
+		}
+		if s.AdjacentParentShardId != nil {
+			isParent[aws.ToString(s.AdjacentParentShardId)] = true
+		}
+	}
+
+	var leaves []types.Shard
+	for _, s := range shards {
+		if !isParent[aws.ToString(s.ShardId)] {
+			leaves = append(leaves, s)
+		}
+	}
+	return leaves
+}
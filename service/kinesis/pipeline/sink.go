@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/aggregation"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// maxPutRecordsBatch is the largest batch sink sends in one PutRecords
+// call, matching Kinesis's per-request record count limit.
+const maxPutRecordsBatch = 500
+
+// sink writes Messages to a destination stream via PutRecords, using
+// record aggregation so many small pipeline outputs still make efficient
+// use of the destination shards.
+type sink struct {
+	client        *kinesis.Client
+	destStreamARN string
+	metrics       MetricsHandler
+	aggregator    *aggregation.Aggregator
+}
+
+func newSink(client *kinesis.Client, destStreamARN string, metrics MetricsHandler) *sink {
+	return &sink{
+		client:        client,
+		destStreamARN: destStreamARN,
+		metrics:       metrics,
+		aggregator:    aggregation.NewAggregator(),
+	}
+}
+
+// Run writes every Message received on in to the destination stream,
+// aggregating through s.aggregator, until in closes or ctx is done.
+func (s *sink) Run(ctx context.Context, in <-chan Message) error {
+	var batch []types.PutRecordsRequestEntry
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := s.client.PutRecords(ctx, &kinesis.PutRecordsInput{
+			StreamARN: aws.String(s.destStreamARN),
+			Records:   batch,
+		})
+		batch = nil
+		return err
+	}
+
+	drain := func() error {
+		if remainder, err := s.aggregator.Flush(); err != nil {
+			return err
+		} else if remainder != nil {
+			batch = append(batch, *remainder)
+		}
+		return flush()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return drain()
+		case msg, ok := <-in:
+			if !ok {
+				return drain()
+			}
+
+			data, err := messageBytes(msg.Value)
+			if err != nil {
+				return fmt.Errorf("pipeline: write to %s: %w", s.destStreamARN, err)
+			}
+
+			full, flushed, err := s.aggregator.Add([]byte(partitionKeyOrDefault(msg)), nil, data)
+			if err != nil {
+				return fmt.Errorf("pipeline: aggregate record for %s: %w", s.destStreamARN, err)
+			}
+			if !full {
+				continue
+			}
+
+			batch = append(batch, *flushed)
+			if len(batch) >= maxPutRecordsBatch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// messageBytes converts a stage's output value into the bytes a Kinesis
+// record carries: []byte and string pass through unchanged, and anything
+// implementing fmt.Stringer uses that. Anything else is rejected -
+// callers with structured output should Map to one of these (e.g. via
+// json.Marshal) before To.
+func messageBytes(v any) ([]byte, error) {
+	switch t := v.(type) {
+	case []byte:
+		return t, nil
+	case string:
+		return []byte(t), nil
+	case fmt.Stringer:
+		return []byte(t.String()), nil
+	default:
+		return nil, fmt.Errorf("%T is not []byte, string, or fmt.Stringer", v)
+	}
+}
+
+func partitionKeyOrDefault(msg Message) string {
+	if msg.PartitionKey != "" {
+		return msg.PartitionKey
+	}
+	if msg.SequenceNumber != "" {
+		return msg.SequenceNumber
+	}
+	return "pipeline"
+}
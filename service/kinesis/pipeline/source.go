@@ -0,0 +1,286 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// pollInterval is how often a polling shard consumer calls GetRecords.
+const pollInterval = 1 * time.Second
+
+// source reads a stream's shards and emits their records as Messages,
+// using SubscribeToShard's push-based enhanced fan-out when a consumer
+// ARN is configured, and polling GetShardIterator/GetRecords otherwise.
+type source struct {
+	client       *kinesis.Client
+	streamARN    string
+	consumerARN  string
+	checkpointer Checkpointer
+	metrics      MetricsHandler
+
+	coordinator *leaseCoordinator
+}
+
+func newSource(client *kinesis.Client, streamARN, consumerARN string, checkpointer Checkpointer, metrics MetricsHandler) *source {
+	return &source{
+		client:       client,
+		streamARN:    streamARN,
+		consumerARN:  consumerARN,
+		checkpointer: checkpointer,
+		metrics:      metrics,
+		coordinator:  newLeaseCoordinator(checkpointer),
+	}
+}
+
+// Start discovers the stream's current leaf shards, claims a lease on
+// each one this worker can acquire, and returns a channel of Messages fed
+// by one goroutine per owned shard. As an owned shard closes due to
+// resharding, its consumer starts its children the same way, so the
+// pipeline follows splits and merges for as long as ctx stays alive.
+func (s *source) Start(ctx context.Context) (<-chan Message, error) {
+	shards, err := discoverShards(ctx, s.client, s.streamARN)
+	if err != nil {
+		return nil, fmt.Errorf("discover shards: %w", err)
+	}
+
+	out := make(chan Message)
+	go s.run(ctx, out, shards)
+	return out, nil
+}
+
+func (s *source) run(ctx context.Context, out chan<- Message, shards []types.Shard) {
+	defer close(out)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+
+	var startShard func(shard types.Shard)
+	startShard = func(shard types.Shard) {
+		shardID := aws.ToString(shard.ShardId)
+
+		mu.Lock()
+		already := seen[shardID]
+		seen[shardID] = true
+		mu.Unlock()
+		if already {
+			return
+		}
+
+		if !s.coordinator.tryAcquire(ctx, shardID) {
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.coordinator.release(ctx, shardID)
+
+			// Renew the lease well within leaseTTL while the shard is read.
+			stopRenew := make(chan struct{})
+			defer close(stopRenew)
+			go func() {
+				ticker := time.NewTicker(leaseTTL / 3)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-stopRenew:
+						return
+					case <-ticker.C:
+						_ = s.checkpointer.RenewLease(ctx, shardID)
+					}
+				}
+			}()
+
+			for _, child := range s.consumeShard(ctx, shard, out) {
+				startShard(child)
+			}
+		}()
+	}
+
+	for _, shard := range shards {
+		startShard(shard)
+	}
+	wg.Wait()
+}
+
+// consumeShard reads shard until it closes due to resharding or ctx is
+// done, returning the child shards to move on to, if any.
+func (s *source) consumeShard(ctx context.Context, shard types.Shard, out chan<- Message) []types.Shard {
+	if s.consumerARN != "" {
+		return s.consumeShardSubscribe(ctx, shard, out)
+	}
+	return s.consumeShardPoll(ctx, shard, out)
+}
+
+func (s *source) consumeShardPoll(ctx context.Context, shard types.Shard, out chan<- Message) []types.Shard {
+	shardID := aws.ToString(shard.ShardId)
+	pos := s.startingPosition(ctx, shardID)
+
+	iterOut, err := s.client.GetShardIterator(ctx, &kinesis.GetShardIteratorInput{
+		StreamARN:              aws.String(s.streamARN),
+		ShardId:                shard.ShardId,
+		ShardIteratorType:      pos.Type,
+		StartingSequenceNumber: pos.SequenceNumber,
+	})
+	if err != nil {
+		return nil
+	}
+	iterator := iterOut.ShardIterator
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	var recordsRead int64
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if iterator == nil {
+			return nil
+		}
+
+		resp, err := s.client.GetRecords(ctx, &kinesis.GetRecordsInput{ShardIterator: iterator})
+		if err != nil {
+			return nil
+		}
+
+		lastSeq := s.emit(ctx, shardID, resp.Records, out)
+		if lastSeq != "" {
+			_ = s.checkpointer.PutCheckpoint(ctx, shardID, lastSeq)
+		}
+		recordsRead += int64(len(resp.Records))
+		s.reportMetrics(shardID, recordsRead, start, aws.ToInt64(resp.MillisBehindLatest))
+
+		if resp.NextShardIterator == nil {
+			return childShards(resp.ChildShards)
+		}
+		iterator = resp.NextShardIterator
+	}
+}
+
+func (s *source) consumeShardSubscribe(ctx context.Context, shard types.Shard, out chan<- Message) []types.Shard {
+	shardID := aws.ToString(shard.ShardId)
+	pos := s.startingPosition(ctx, shardID)
+
+	resp, err := s.client.SubscribeToShard(ctx, &kinesis.SubscribeToShardInput{
+		ConsumerARN:      aws.String(s.consumerARN),
+		ShardId:          shard.ShardId,
+		StartingPosition: pos,
+	})
+	if err != nil {
+		return nil
+	}
+	stream := resp.GetStream()
+	defer stream.Close()
+
+	start := time.Now()
+	var recordsRead int64
+	var children []types.ChildShard
+	for event := range stream.Events() {
+		e, ok := event.(*types.SubscribeToShardEventStreamMemberSubscribeToShardEvent)
+		if !ok {
+			continue
+		}
+
+		lastSeq := s.emit(ctx, shardID, e.Value.Records, out)
+		if lastSeq != "" {
+			_ = s.checkpointer.PutCheckpoint(ctx, shardID, lastSeq)
+		}
+		recordsRead += int64(len(e.Value.Records))
+		s.reportMetrics(shardID, recordsRead, start, aws.ToInt64(e.Value.MillisBehindLatest))
+		children = e.Value.ChildShards
+	}
+	if stream.Err() != nil {
+		return nil
+	}
+	return childShards(children)
+}
+
+// childShards converts the ChildShard values a shard reports on closing
+// (GetRecordsOutput.ChildShards, SubscribeToShardEvent.ChildShards) into
+// Shards, so startShard can recurse into them the same way it does the
+// stream's initial leaf shards.
+func childShards(children []types.ChildShard) []types.Shard {
+	shards := make([]types.Shard, 0, len(children))
+	for _, c := range children {
+		shards = append(shards, types.Shard{
+			ShardId:       c.ShardId,
+			HashKeyRange:  c.HashKeyRange,
+			ParentShardId: firstParentShardID(c.ParentShards),
+		})
+	}
+	return shards
+}
+
+// firstParentShardID returns the first of a ChildShard's parent shard IDs,
+// matching the single ParentShardId a Shard carries - a merge's second
+// parent is still reachable via ParentShards on the ChildShard itself.
+func firstParentShardID(parentShardIDs []string) *string {
+	if len(parentShardIDs) == 0 {
+		return nil
+	}
+	return aws.String(parentShardIDs[0])
+}
+
+// emit writes records to out as Messages, returning the last one's
+// sequence number so the caller can checkpoint it, or "" if records is
+// empty.
+func (s *source) emit(ctx context.Context, shardID string, records []types.Record, out chan<- Message) string {
+	var lastSeq string
+	for _, r := range records {
+		select {
+		case out <- recordMessage(shardID, r):
+		case <-ctx.Done():
+			return lastSeq
+		}
+		lastSeq = aws.ToString(r.SequenceNumber)
+	}
+	return lastSeq
+}
+
+func (s *source) reportMetrics(shardID string, recordsRead int64, start time.Time, millisBehindLatest int64) {
+	if s.metrics == nil {
+		return
+	}
+	elapsed := time.Since(start).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	s.metrics.HandleMetrics(Metrics{
+		ShardID:            shardID,
+		RecordsPerSecond:   float64(recordsRead) / elapsed,
+		MillisBehindLatest: millisBehindLatest,
+	})
+}
+
+// startingPosition resumes from shardID's last checkpoint if one exists,
+// or starts from the oldest available record otherwise.
+func (s *source) startingPosition(ctx context.Context, shardID string) *types.StartingPosition {
+	if seq, err := s.checkpointer.GetCheckpoint(ctx, shardID); err == nil && seq != "" {
+		return &types.StartingPosition{
+			Type:           types.ShardIteratorTypeAfterSequenceNumber,
+			SequenceNumber: aws.String(seq),
+		}
+	}
+	return &types.StartingPosition{Type: types.ShardIteratorTypeTrimHorizon}
+}
+
+func recordMessage(shardID string, r types.Record) Message {
+	return Message{
+		Value:                       r.Data,
+		PartitionKey:                aws.ToString(r.PartitionKey),
+		SequenceNumber:              aws.ToString(r.SequenceNumber),
+		ShardID:                     shardID,
+		ApproximateArrivalTimestamp: aws.ToTime(r.ApproximateArrivalTimestamp),
+	}
+}
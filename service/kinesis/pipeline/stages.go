@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+// mapStage applies fn to every Message's Value in place.
+func mapStage(fn func(any) (any, error)) stage {
+	return func(ctx context.Context, in <-chan Message, errs chan<- error) <-chan Message {
+		out := make(chan Message)
+		go func() {
+			defer close(out)
+			for msg := range in {
+				v, err := fn(msg.Value)
+				if err != nil {
+					reportError(errs, err)
+					continue
+				}
+				msg.Value = v
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// filterStage drops every Message for which fn returns false.
+func filterStage(fn func(any) bool) stage {
+	return func(ctx context.Context, in <-chan Message, errs chan<- error) <-chan Message {
+		out := make(chan Message)
+		go func() {
+			defer close(out)
+			for msg := range in {
+				if !fn(msg.Value) {
+					continue
+				}
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// batchStage groups every size consecutive values into a single
+// []any-valued Message, which carries the metadata of the last value in
+// the group.
+func batchStage(size int) stage {
+	return func(ctx context.Context, in <-chan Message, errs chan<- error) <-chan Message {
+		out := make(chan Message)
+		go func() {
+			defer close(out)
+
+			var buf []any
+			var last Message
+			for msg := range in {
+				buf = append(buf, msg.Value)
+				last = msg
+				if len(buf) < size {
+					continue
+				}
+				batched := last
+				batched.Value = buf
+				buf = nil
+				select {
+				case out <- batched:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// windowStage groups every value received within d into a single
+// []any-valued Message, flushing on whichever comes first: the timer
+// firing, or the upstream closing.
+func windowStage(d time.Duration) stage {
+	return func(ctx context.Context, in <-chan Message, errs chan<- error) <-chan Message {
+		out := make(chan Message)
+		go func() {
+			defer close(out)
+
+			ticker := time.NewTicker(d)
+			defer ticker.Stop()
+
+			var buf []any
+			var last Message
+			flush := func() bool {
+				if len(buf) == 0 {
+					return true
+				}
+				batched := last
+				batched.Value = buf
+				buf = nil
+				select {
+				case out <- batched:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if !flush() {
+						return
+					}
+				case msg, ok := <-in:
+					if !ok {
+						flush()
+						return
+					}
+					buf = append(buf, msg.Value)
+					last = msg
+				}
+			}
+		}()
+		return out
+	}
+}
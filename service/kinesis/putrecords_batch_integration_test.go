@@ -0,0 +1,90 @@
+package kinesis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// fakePutRecordsHandler terminates the Initialize chain as if it were the
+// rest of the operation stack, recording every batch that actually reached
+// it so a test can assert on how splitting sliced up the original request.
+type fakePutRecordsHandler struct {
+	batches [][]types.PutRecordsRequestEntry
+}
+
+func (h *fakePutRecordsHandler) HandleInitialize(ctx context.Context, in middleware.InitializeInput) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	input := in.Parameters.(*PutRecordsInput)
+	h.batches = append(h.batches, input.Records)
+	out.Result = &PutRecordsOutput{FailedRecordCount: aws.Int32(0)}
+	return out, metadata, nil
+}
+
+func putRecordsOf(n int) *PutRecordsInput {
+	input := &PutRecordsInput{StreamName: aws.String("s")}
+	for i := 0; i < n; i++ {
+		input.Records = append(input.Records, makeEntry(10))
+	}
+	return input
+}
+
+// runPutRecordsThroughSplitAndBatchLimits chains splitPutRecordsMiddleware
+// and validateOpPutRecordsBatchLimits the same way
+// addOperationPutRecordsMiddlewares registers them on the real stack, ending
+// in handler instead of an actual PutRecords call.
+func runPutRecordsThroughSplitAndBatchLimits(options Options, input *PutRecordsInput, handler *fakePutRecordsHandler) error {
+	split := &splitPutRecordsMiddleware{options: options}
+	validate := &validateOpPutRecordsBatchLimits{options: options}
+
+	next := middleware.InitializeHandlerFunc(func(ctx context.Context, in middleware.InitializeInput) (middleware.InitializeOutput, middleware.Metadata, error) {
+		return validate.HandleInitialize(ctx, in, handler)
+	})
+	_, _, err := split.HandleInitialize(context.Background(), middleware.InitializeInput{Parameters: input}, next)
+	return err
+}
+
+// TestPutRecordsOversizeBatchIsSplitWhenAutoSplitEnabled proves that, wired
+// the way addOperationPutRecordsMiddlewares wires them, a 600-record
+// PutRecords (over the service's 500-record limit) is transparently broken
+// into multiple in-limit calls instead of being rejected, once
+// AutoSplitPutRecords is enabled.
+func TestPutRecordsOversizeBatchIsSplitWhenAutoSplitEnabled(t *testing.T) {
+	handler := &fakePutRecordsHandler{}
+	err := runPutRecordsThroughSplitAndBatchLimits(Options{AutoSplitPutRecords: true}, putRecordsOf(600), handler)
+	if err != nil {
+		t.Fatalf("HandleInitialize() error = %v, want nil", err)
+	}
+	if len(handler.batches) != 2 {
+		t.Fatalf("handler saw %d batches, want 2", len(handler.batches))
+	}
+	var total int
+	for i, b := range handler.batches {
+		if len(b) > maxPutRecordsRecords {
+			t.Errorf("batch %d has %d records, want <= %d", i, len(b), maxPutRecordsRecords)
+		}
+		total += len(b)
+	}
+	if total != 600 {
+		t.Errorf("batches carried %d records total, want 600", total)
+	}
+}
+
+// TestPutRecordsOversizeBatchIsRejectedWhenAutoSplitDisabled proves the
+// same 600-record PutRecords is rejected by validateOpPutRecordsBatchLimits
+// instead of silently reaching the wire when AutoSplitPutRecords is left
+// off.
+func TestPutRecordsOversizeBatchIsRejectedWhenAutoSplitDisabled(t *testing.T) {
+	handler := &fakePutRecordsHandler{}
+	err := runPutRecordsThroughSplitAndBatchLimits(Options{}, putRecordsOf(600), handler)
+	if err == nil {
+		t.Fatal("HandleInitialize() error = nil, want a batch-limits validation error")
+	}
+	if len(handler.batches) != 0 {
+		t.Errorf("handler saw %d batches, want 0 (request should have been rejected before reaching it)", len(handler.batches))
+	}
+}
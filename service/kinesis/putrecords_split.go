@@ -0,0 +1,135 @@
+package kinesis
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// splitPutRecordsBatches partitions input.Records into the fewest
+// sub-batches that each satisfy the PutRecords count and aggregate size
+// limits, preserving record order. A single record that itself exceeds
+// maxPutRecordsRecordBytes is left as-is in whichever batch it falls into
+// (other records may share that batch); it will still be rejected by
+// validatePutRecordsBatchLimits, since no amount of splitting can fix that.
+func splitPutRecordsBatches(input *PutRecordsInput) []*PutRecordsInput {
+	if len(input.Records) == 0 {
+		return []*PutRecordsInput{input}
+	}
+
+	var batches []*PutRecordsInput
+	start := 0
+	aggregate := 0
+	for i, r := range input.Records {
+		size := putRecordsRequestEntrySize(r)
+
+		if i > start && (i-start >= maxPutRecordsRecords || aggregate+size > maxPutRecordsAggregateBytes) {
+			batches = append(batches, putRecordsBatch(input, input.Records[start:i]))
+			start = i
+			aggregate = 0
+		}
+		aggregate += size
+	}
+	batches = append(batches, putRecordsBatch(input, input.Records[start:]))
+
+	return batches
+}
+
+// putRecordsBatch copies input with Records replaced by records, so every
+// other field (StreamName, StreamARN) carries over to each sub-batch.
+func putRecordsBatch(input *PutRecordsInput, records []types.PutRecordsRequestEntry) *PutRecordsInput {
+	batch := *input
+	batch.Records = records
+	return &batch
+}
+
+// exceedsPutRecordsBatchLimits reports whether input breaks the PutRecords
+// record-count or aggregate-size limit, i.e. whether
+// splitPutRecordsMiddleware has anything to do. A record over the
+// per-record limit does not count here, since splitting can't fix it.
+func exceedsPutRecordsBatchLimits(input *PutRecordsInput) bool {
+	if len(input.Records) > maxPutRecordsRecords {
+		return true
+	}
+
+	var aggregate int
+	for _, r := range input.Records {
+		aggregate += putRecordsRequestEntrySize(r)
+	}
+	return aggregate > maxPutRecordsAggregateBytes
+}
+
+// mergePutRecordsOutputs concatenates Records and sums FailedRecordCount
+// across the outputs of the sub-batches a split PutRecords call was broken
+// into, so the caller sees one PutRecordsOutput whose Records line up
+// index-for-index with the original, unsplit request.
+func mergePutRecordsOutputs(outputs []*PutRecordsOutput) *PutRecordsOutput {
+	merged := &PutRecordsOutput{}
+	var failed int32
+	for _, out := range outputs {
+		if out == nil {
+			continue
+		}
+		merged.Records = append(merged.Records, out.Records...)
+		failed += aws.ToInt32(out.FailedRecordCount)
+		merged.EncryptionType = out.EncryptionType
+		merged.ResultMetadata = out.ResultMetadata
+	}
+	merged.FailedRecordCount = aws.Int32(failed)
+	return merged
+}
+
+// splitPutRecordsMiddleware transparently breaks a PutRecords call that
+// exceeds the service's count/size limits into multiple sequential
+// PutRecords calls, then merges their outputs, so callers building large
+// in-memory batches don't each have to reimplement batching themselves. It
+// only activates when Options.AutoSplitPutRecords is enabled; otherwise
+// oversize batches are left for validateOpPutRecordsBatchLimits to reject.
+type splitPutRecordsMiddleware struct {
+	options Options
+}
+
+func (*splitPutRecordsMiddleware) ID() string {
+	return "SplitPutRecords"
+}
+
+func (m *splitPutRecordsMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	if !m.options.AutoSplitPutRecords {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	input, ok := in.Parameters.(*PutRecordsInput)
+	if !ok || !exceedsPutRecordsBatchLimits(input) {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	batches := splitPutRecordsBatches(input)
+	outputs := make([]*PutRecordsOutput, 0, len(batches))
+	for _, batch := range batches {
+		batchOut, batchMetadata, err := next.HandleInitialize(ctx, middleware.InitializeInput{Parameters: batch})
+		metadata = batchMetadata
+		if err != nil {
+			return out, metadata, err
+		}
+		output, ok := batchOut.Result.(*PutRecordsOutput)
+		if !ok {
+			return out, metadata, err
+		}
+		outputs = append(outputs, output)
+	}
+
+	out.Result = mergePutRecordsOutputs(outputs)
+	return out, metadata, nil
+}
+
+// addOpPutRecordsSplitMiddleware registers splitPutRecordsMiddleware ahead
+// of validation, so a batch that AutoSplitPutRecords would otherwise fix up
+// isn't rejected by validateOpPutRecordsBatchLimits before it gets the
+// chance to be split.
+func addOpPutRecordsSplitMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Initialize.Add(&splitPutRecordsMiddleware{options: options}, middleware.Before)
+}
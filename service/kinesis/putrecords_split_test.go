@@ -0,0 +1,121 @@
+package kinesis
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+func makeEntry(dataLen int) types.PutRecordsRequestEntry {
+	return types.PutRecordsRequestEntry{
+		Data:         make([]byte, dataLen),
+		PartitionKey: aws.String("k"),
+	}
+}
+
+func TestSplitPutRecordsBatchesByCount(t *testing.T) {
+	input := &PutRecordsInput{StreamName: aws.String("s")}
+	for i := 0; i < maxPutRecordsRecords+1; i++ {
+		input.Records = append(input.Records, makeEntry(1))
+	}
+
+	batches := splitPutRecordsBatches(input)
+	if len(batches) != 2 {
+		t.Fatalf("splitPutRecordsBatches() returned %d batches, want 2", len(batches))
+	}
+	if len(batches[0].Records) != maxPutRecordsRecords {
+		t.Errorf("batch 0 has %d records, want %d", len(batches[0].Records), maxPutRecordsRecords)
+	}
+	if len(batches[1].Records) != 1 {
+		t.Errorf("batch 1 has %d records, want 1", len(batches[1].Records))
+	}
+	for i, b := range batches {
+		if aws.ToString(b.StreamName) != "s" {
+			t.Errorf("batch %d StreamName = %q, want %q", i, aws.ToString(b.StreamName), "s")
+		}
+	}
+}
+
+func TestSplitPutRecordsBatchesBySize(t *testing.T) {
+	input := &PutRecordsInput{StreamName: aws.String("s")}
+	// Each entry's size also counts its 1-byte partition key "k", so three
+	// entries fill the aggregate limit exactly and the fourth spills over.
+	perRecord := maxPutRecordsAggregateBytes/3 - len("k")
+	for i := 0; i < 4; i++ {
+		input.Records = append(input.Records, makeEntry(perRecord))
+	}
+
+	batches := splitPutRecordsBatches(input)
+	if len(batches) != 2 {
+		t.Fatalf("splitPutRecordsBatches() returned %d batches, want 2", len(batches))
+	}
+	if len(batches[0].Records) != 3 {
+		t.Errorf("batch 0 has %d records, want 3", len(batches[0].Records))
+	}
+	if len(batches[1].Records) != 1 {
+		t.Errorf("batch 1 has %d records, want 1", len(batches[1].Records))
+	}
+}
+
+func TestSplitPutRecordsBatchesNoSplitNeeded(t *testing.T) {
+	input := &PutRecordsInput{StreamName: aws.String("s")}
+	input.Records = append(input.Records, makeEntry(10), makeEntry(10))
+
+	batches := splitPutRecordsBatches(input)
+	if len(batches) != 1 {
+		t.Fatalf("splitPutRecordsBatches() returned %d batches, want 1", len(batches))
+	}
+	if len(batches[0].Records) != 2 {
+		t.Errorf("batch 0 has %d records, want 2", len(batches[0].Records))
+	}
+}
+
+func TestSplitPutRecordsBatchesOversizeRecordAlone(t *testing.T) {
+	input := &PutRecordsInput{StreamName: aws.String("s")}
+	input.Records = append(input.Records, makeEntry(maxPutRecordsRecordBytes+1))
+
+	batches := splitPutRecordsBatches(input)
+	if len(batches) != 1 {
+		t.Fatalf("splitPutRecordsBatches() returned %d batches, want 1", len(batches))
+	}
+	if len(batches[0].Records) != 1 {
+		t.Errorf("batch 0 has %d records, want 1", len(batches[0].Records))
+	}
+}
+
+func TestExceedsPutRecordsBatchLimits(t *testing.T) {
+	small := &PutRecordsInput{Records: []types.PutRecordsRequestEntry{makeEntry(10)}}
+	if exceedsPutRecordsBatchLimits(small) {
+		t.Error("exceedsPutRecordsBatchLimits() = true for a small batch, want false")
+	}
+
+	var big []types.PutRecordsRequestEntry
+	for i := 0; i < maxPutRecordsRecords+1; i++ {
+		big = append(big, makeEntry(1))
+	}
+	if !exceedsPutRecordsBatchLimits(&PutRecordsInput{Records: big}) {
+		t.Error("exceedsPutRecordsBatchLimits() = false for a batch over the count limit, want true")
+	}
+}
+
+func TestMergePutRecordsOutputs(t *testing.T) {
+	outputs := []*PutRecordsOutput{
+		{
+			Records:           []types.PutRecordsResultEntry{{SequenceNumber: aws.String("1")}},
+			FailedRecordCount: aws.Int32(0),
+		},
+		{
+			Records:           []types.PutRecordsResultEntry{{SequenceNumber: aws.String("2")}, {SequenceNumber: aws.String("3")}},
+			FailedRecordCount: aws.Int32(1),
+		},
+	}
+
+	merged := mergePutRecordsOutputs(outputs)
+	if len(merged.Records) != 3 {
+		t.Fatalf("mergePutRecordsOutputs() has %d records, want 3", len(merged.Records))
+	}
+	if aws.ToInt32(merged.FailedRecordCount) != 1 {
+		t.Errorf("mergePutRecordsOutputs() FailedRecordCount = %d, want 1", aws.ToInt32(merged.FailedRecordCount))
+	}
+}
@@ -0,0 +1,106 @@
+package kinesis
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/aggregation"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// WithRecordAggregation enables KPL-style record aggregation for
+// PutRecords: each entry in a request is buffered into agg rather than
+// sent as-is, and replaced with whatever complete aggregated
+// PutRecordsRequestEntry values that buffering produces. Since an entry
+// only comes out once agg's size or count threshold is hit,
+// PutRecordsInput.Records may shrink to fewer entries than were passed in,
+// or to none at all if nothing has filled up yet - callers that need the
+// last partial batch sent regardless should call agg.Flush and issue a
+// PutRecords request with the result themselves (for example, on a
+// ticker), since this middleware only runs while a PutRecords call is in
+// flight.
+//
+// agg is meant to be shared across every PutRecords call made with this
+// option - typically one Aggregator per stream, reused for the life of the
+// client.
+func WithRecordAggregation(agg *aggregation.Aggregator) func(*Options) {
+	return func(o *Options) {
+		o.RecordAggregation = agg
+	}
+}
+
+// recordAggregationMiddleware implements the request-rewriting side of
+// WithRecordAggregation: it runs before the generated PutRecords
+// validators and marshalers, since by the time they run Records must
+// already be the aggregated entries that are actually going on the wire.
+type recordAggregationMiddleware struct {
+	options Options
+}
+
+func (*recordAggregationMiddleware) ID() string {
+	return "RecordAggregation"
+}
+
+func (m *recordAggregationMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	agg := m.options.RecordAggregation
+	if agg == nil {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	input, ok := in.Parameters.(*PutRecordsInput)
+	if !ok {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	var info aggregation.AggregationBatchInfo
+	var entries []types.PutRecordsRequestEntry
+	for _, r := range input.Records {
+		full, flushed, aggErr := agg.Add([]byte(aws.ToString(r.PartitionKey)), []byte(aws.ToString(r.ExplicitHashKey)), r.Data)
+		if aggErr != nil {
+			return out, metadata, aggErr
+		}
+		if !full {
+			continue
+		}
+		entries = append(entries, *flushed)
+		info.Entries = append(info.Entries, aggregation.AggregationBatchEntry{RecordCount: flushedRecordCount(flushed)})
+	}
+	input.Records = entries
+
+	if len(entries) == 0 {
+		// Every record landed in agg's buffer with nothing flushed yet - an
+		// empty-Records PutRecords call is itself invalid, and there's
+		// nothing to put on the wire. Report success without calling next,
+		// but hand back a synthetic output rather than a nil one so callers
+		// checking out.FailedRecordCount don't nil-deref.
+		out.Result = &PutRecordsOutput{FailedRecordCount: aws.Int32(0)}
+		aggregation.SetAggregationBatchInfo(&metadata, info)
+		return out, metadata, nil
+	}
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	aggregation.SetAggregationBatchInfo(&metadata, info)
+	return out, metadata, err
+}
+
+// flushedRecordCount decodes the aggregated entry just flushed to report
+// how many logical records it packed, since Aggregator.Add's fixed
+// signature doesn't carry that count out directly.
+func flushedRecordCount(entry *types.PutRecordsRequestEntry) int {
+	deagg, err := aggregation.NewDeaggregator().Deaggregate(entry.Data)
+	if err != nil {
+		return 1
+	}
+	return len(deagg.Records)
+}
+
+// addRecordAggregationMiddleware registers recordAggregationMiddleware at
+// the front of the Initialize step, ahead of every other PutRecords
+// validator and middleware in this package, so they all see the
+// already-aggregated entries.
+func addRecordAggregationMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Initialize.Add(&recordAggregationMiddleware{options: options}, middleware.Before)
+}
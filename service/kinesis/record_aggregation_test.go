@@ -0,0 +1,113 @@
+package kinesis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/aggregation"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// fakePutRecordsNextHandler is a middleware.InitializeHandler that records
+// the PutRecordsInput it was handed instead of sending it to the service.
+type fakePutRecordsNextHandler struct {
+	got *PutRecordsInput
+}
+
+func (h *fakePutRecordsNextHandler) HandleInitialize(ctx context.Context, in middleware.InitializeInput) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	h.got = in.Parameters.(*PutRecordsInput)
+	out.Result = &PutRecordsOutput{FailedRecordCount: aws.Int32(0)}
+	return out, metadata, nil
+}
+
+// fakeGetRecordsNextHandler is a middleware.DeserializeHandler that returns
+// a canned GetRecordsOutput, standing in for the generated deserializer.
+type fakeGetRecordsNextHandler struct {
+	output *GetRecordsOutput
+}
+
+func (h *fakeGetRecordsNextHandler) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out.Result = h.output
+	return out, metadata, nil
+}
+
+// TestRecordAggregationMiddlewareWiresIntoPutRecords proves
+// recordAggregationMiddleware - reached via
+// addOperationPutRecordsMiddlewares - actually replaces a PutRecordsInput's
+// Records with the aggregated entries its Aggregator produces, rather than
+// passing the caller's records through untouched.
+func TestRecordAggregationMiddlewareWiresIntoPutRecords(t *testing.T) {
+	agg := aggregation.NewAggregator()
+	agg.MaxRecords = 1
+	m := &recordAggregationMiddleware{options: Options{RecordAggregation: agg}}
+
+	// With MaxRecords=1, the second record's Add call flushes the first
+	// (the aggregator only flushes once something is already buffered), so
+	// two input records become one aggregated entry downstream, with the
+	// second still held back in agg for a later call.
+	input := &PutRecordsInput{
+		StreamName: aws.String("s"),
+		Records: []types.PutRecordsRequestEntry{
+			{PartitionKey: aws.String("a"), Data: []byte("hello")},
+			{PartitionKey: aws.String("b"), Data: []byte("world")},
+		},
+	}
+
+	next := &fakePutRecordsNextHandler{}
+	if _, _, err := m.HandleInitialize(context.Background(), middleware.InitializeInput{Parameters: input}, next); err != nil {
+		t.Fatalf("HandleInitialize() error = %v", err)
+	}
+
+	if next.got == nil {
+		t.Fatal("next handler never saw a PutRecordsInput")
+	}
+	if len(next.got.Records) != 1 {
+		t.Fatalf("got %d records downstream, want 1 aggregated entry", len(next.got.Records))
+	}
+	if !aggregation.IsAggregated(next.got.Records[0].Data) {
+		t.Error("downstream entry's Data isn't KPL-aggregated, want the entry recordAggregationMiddleware built")
+	}
+}
+
+// TestDeaggregationMiddlewareWiresIntoGetRecords proves
+// deaggregationMiddleware - reached via addOperationGetRecordsMiddlewares -
+// actually expands an aggregated GetRecordsOutput record in place, rather
+// than leaving it for the caller to deaggregate themselves.
+func TestDeaggregationMiddlewareWiresIntoGetRecords(t *testing.T) {
+	agg := aggregation.NewAggregator()
+	agg.MaxRecords = 1
+	if _, _, err := agg.Add([]byte("a"), nil, []byte("hello")); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	flushed, err := agg.Flush()
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	m := &deaggregationMiddleware{options: Options{AutomaticDeaggregation: aggregation.NewDeaggregator()}}
+	next := &fakeGetRecordsNextHandler{output: &GetRecordsOutput{
+		Records: []types.Record{{PartitionKey: aws.String("unused"), Data: flushed.Data}},
+	}}
+
+	out, _, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{}, next)
+	if err != nil {
+		t.Fatalf("HandleDeserialize() error = %v", err)
+	}
+
+	result, ok := out.Result.(*GetRecordsOutput)
+	if !ok {
+		t.Fatalf("out.Result is %T, want *GetRecordsOutput", out.Result)
+	}
+	if len(result.Records) != 1 {
+		t.Fatalf("got %d records, want 1 deaggregated record", len(result.Records))
+	}
+	if string(result.Records[0].Data) != "hello" {
+		t.Errorf("Records[0].Data = %q, want %q", result.Records[0].Data, "hello")
+	}
+}
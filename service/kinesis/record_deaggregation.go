@@ -0,0 +1,93 @@
+package kinesis
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/aggregation"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// WithAutomaticDeaggregation enables transparent KPL deaggregation of
+// GetRecords output: any returned types.Record whose Data carries the
+// aggregation magic prefix is expanded in place into the logical records
+// it packed, each inheriting the parent record's SequenceNumber,
+// ApproximateArrivalTimestamp, and EncryptionType. Records that aren't
+// aggregated are returned unchanged. Pass nil to disable (the default);
+// pass a non-nil *aggregation.Deaggregator to enable it, configured as
+// that Deaggregator specifies (for example, SkipChecksum).
+//
+// SubscribeToShard's event stream isn't covered here - expanding its
+// SubscribeToShardEvent.Records the same way would need the equivalent
+// hook in the event stream reader, not the Deserialize step this
+// middleware uses.
+func WithAutomaticDeaggregation(deagg *aggregation.Deaggregator) func(*Options) {
+	return func(o *Options) {
+		o.AutomaticDeaggregation = deagg
+	}
+}
+
+// deaggregationMiddleware expands aggregated records in a GetRecordsOutput
+// after the generated deserializer has built it.
+type deaggregationMiddleware struct {
+	options Options
+}
+
+func (*deaggregationMiddleware) ID() string {
+	return "AutomaticDeaggregation"
+}
+
+func (m *deaggregationMiddleware) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil || m.options.AutomaticDeaggregation == nil {
+		return out, metadata, err
+	}
+
+	output, ok := out.Result.(*GetRecordsOutput)
+	if !ok {
+		return out, metadata, err
+	}
+
+	expanded, dErr := deaggregateRecords(m.options.AutomaticDeaggregation, output.Records)
+	if dErr != nil {
+		return out, metadata, dErr
+	}
+	output.Records = expanded
+
+	return out, metadata, nil
+}
+
+// deaggregateRecords expands every aggregated record in records, leaving
+// ordinary records untouched.
+func deaggregateRecords(deagg *aggregation.Deaggregator, records []types.Record) ([]types.Record, error) {
+	expanded := make([]types.Record, 0, len(records))
+	for _, r := range records {
+		if !aggregation.IsAggregated(r.Data) {
+			expanded = append(expanded, r)
+			continue
+		}
+
+		agg, err := deagg.Deaggregate(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range agg.Records {
+			child := r
+			child.Data = sub.Data
+			if sub.PartitionKey != "" {
+				child.PartitionKey = &sub.PartitionKey
+			}
+			expanded = append(expanded, child)
+		}
+	}
+	return expanded, nil
+}
+
+// addOpGetRecordsDeaggregationMiddleware registers deaggregationMiddleware
+// at the end of the Deserialize step, so it sees the fully-populated
+// GetRecordsOutput the generated deserializer produces.
+func addOpGetRecordsDeaggregationMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Deserialize.Add(&deaggregationMiddleware{options: options}, middleware.After)
+}
@@ -0,0 +1,200 @@
+package kinesis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Bounds the generated validators don't check because they only verify a
+// field is present, not that its value is one Kinesis will actually
+// accept.
+const (
+	maxPutRecordDataBytes = 1 << 20 // 1 MiB
+	minPartitionKeyBytes  = 1
+	maxPartitionKeyBytes  = 256
+	minRetentionPeriodHrs = 24
+	maxRetentionPeriodHrs = 8760
+)
+
+// validatePartitionKeyField adds an error to invalidParams if key, once
+// present, isn't the 1-256 byte length Kinesis documents for partition
+// keys.
+func validatePartitionKeyField(fieldName string, key *string, invalidParams *smithy.InvalidParamsError) {
+	if key == nil {
+		return
+	}
+	n := len(*key)
+	if n < minPartitionKeyBytes || n > maxPartitionKeyBytes {
+		invalidParams.Add(fmt.Errorf("%s: length %d is outside the valid range [%d, %d]", fieldName, n, minPartitionKeyBytes, maxPartitionKeyBytes))
+	}
+}
+
+// validateRetentionPeriodField adds an error to invalidParams if hours,
+// once present, falls outside Kinesis's documented retention period range
+// of 24 to 8760 hours (1 to 365 days).
+func validateRetentionPeriodField(fieldName string, hours *int32, invalidParams *smithy.InvalidParamsError) {
+	if hours == nil {
+		return
+	}
+	if *hours < minRetentionPeriodHrs || *hours > maxRetentionPeriodHrs {
+		invalidParams.Add(fmt.Errorf("%s: %d is outside the valid range [%d, %d]", fieldName, *hours, minRetentionPeriodHrs, maxRetentionPeriodHrs))
+	}
+}
+
+// validateKinesisARNField adds an error to invalidParams if s, once
+// present, doesn't parse as an ARN, or parses as one for a service other
+// than Kinesis.
+func validateKinesisARNField(fieldName string, s *string, invalidParams *smithy.InvalidParamsError) {
+	if s == nil || *s == "" {
+		return
+	}
+	parsed, err := arn.Parse(*s)
+	if err != nil {
+		invalidParams.Add(fmt.Errorf("%s: %w", fieldName, err))
+		return
+	}
+	if parsed.Service != "kinesis" {
+		invalidParams.Add(fmt.Errorf("%s: expected a kinesis ARN, got service %q", fieldName, parsed.Service))
+	}
+}
+
+// validateStrictInput runs the additional, stricter-than-generated checks
+// for the operations that have them. Operations without a case are left
+// alone; this is additive to, never a replacement for, the generated and
+// hand-written validators already in place.
+func validateStrictInput(opName string, params interface{}) error {
+	invalidParams := smithy.InvalidParamsError{Context: opName}
+
+	switch v := params.(type) {
+	case *PutRecordInput:
+		if len(v.Data) > maxPutRecordDataBytes {
+			invalidParams.Add(fmt.Errorf("Data: size %d bytes exceeds the PutRecord limit of %d bytes", len(v.Data), maxPutRecordDataBytes))
+		}
+		validatePartitionKeyField("PartitionKey", v.PartitionKey, &invalidParams)
+
+	case *PutRecordsInput:
+		if len(v.Records) < 1 || len(v.Records) > maxPutRecordsRecords {
+			invalidParams.Add(fmt.Errorf("Records: length %d is outside the valid range [%d, %d]", len(v.Records), 1, maxPutRecordsRecords))
+		}
+
+		var aggregate int
+		for i := range v.Records {
+			aggregate += putRecordsRequestEntrySize(v.Records[i])
+
+			entryParams := smithy.InvalidParamsError{Context: "PutRecordsRequestEntry"}
+			validatePartitionKeyField("PartitionKey", v.Records[i].PartitionKey, &entryParams)
+			if entryParams.Len() > 0 {
+				invalidParams.AddNested(fmt.Sprintf("Records[%d]", i), entryParams)
+			}
+		}
+		if aggregate > maxPutRecordsAggregateBytes {
+			invalidParams.Add(fmt.Errorf("Records: aggregate size %d bytes exceeds the PutRecords limit of %d bytes", aggregate, maxPutRecordsAggregateBytes))
+		}
+
+	case *IncreaseStreamRetentionPeriodInput:
+		validateRetentionPeriodField("RetentionPeriodHours", v.RetentionPeriodHours, &invalidParams)
+
+	case *DecreaseStreamRetentionPeriodInput:
+		validateRetentionPeriodField("RetentionPeriodHours", v.RetentionPeriodHours, &invalidParams)
+
+	case *UpdateShardCountInput:
+		if v.TargetShardCount != nil && *v.TargetShardCount < 1 {
+			invalidParams.Add(fmt.Errorf("TargetShardCount: %d must be at least 1", *v.TargetShardCount))
+		}
+
+	case *SubscribeToShardInput:
+		validateKinesisARNField("ConsumerARN", v.ConsumerARN, &invalidParams)
+		if v.StartingPosition != nil && v.StartingPosition.Timestamp != nil && v.StartingPosition.Timestamp.After(time.Now()) {
+			invalidParams.Add(fmt.Errorf("StartingPosition.Timestamp: %s is in the future", aws.ToTime(v.StartingPosition.Timestamp)))
+		}
+
+	case *RegisterStreamConsumerInput:
+		validateKinesisARNField("StreamARN", v.StreamARN, &invalidParams)
+
+	case *ListStreamConsumersInput:
+		validateKinesisARNField("StreamARN", v.StreamARN, &invalidParams)
+
+	case *DeleteResourcePolicyInput:
+		validateKinesisARNField("ResourceARN", v.ResourceARN, &invalidParams)
+
+	case *GetResourcePolicyInput:
+		validateKinesisARNField("ResourceARN", v.ResourceARN, &invalidParams)
+
+	case *PutResourcePolicyInput:
+		validateKinesisARNField("ResourceARN", v.ResourceARN, &invalidParams)
+
+	case *UpdateStreamModeInput:
+		validateKinesisARNField("StreamARN", v.StreamARN, &invalidParams)
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// validateOpStrictInput is a hand-written companion to the generated
+// validators, run after them on the stacks that register it, that enforces the
+// value-level constraints validateStrictInput checks instead of the
+// presence-only checks the generated validators perform. It can be turned
+// off per call via WithStrictInputValidation(false) for callers who need to
+// send input the SDK's stricter checks reject but the service itself
+// accepts.
+type validateOpStrictInput struct {
+	options Options
+}
+
+func (*validateOpStrictInput) ID() string {
+	return "StrictInputValidation"
+}
+
+func (m *validateOpStrictInput) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	opName := middleware.GetOperationName(ctx)
+	if !m.options.strictInputValidationEnabled() || m.options.isValidationDisabled(opName) {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	if err := validateStrictInput(opName, in.Parameters); err != nil {
+		return out, metadata, err
+	}
+
+	return next.HandleInitialize(ctx, in)
+}
+
+// addStrictInputValidationMiddleware registers validateOpStrictInput at the
+// end of the Initialize step. It is called from
+// addOperationPutRecordsMiddlewares and addOperationGetRecordsMiddlewares
+// only, so the cases validateStrictInput has for other operations do not
+// run on any stack this package sets up.
+func addStrictInputValidationMiddleware(stack *middleware.Stack, options Options) error {
+	return stack.Initialize.Add(&validateOpStrictInput{options: options}, middleware.After)
+}
+
+// WithStrictInputValidation toggles validateOpStrictInput's extra,
+// value-level checks (partition key length, retention period bounds, ARN
+// shape, and so on) on top of the SDK's always-on presence checks.
+// Strict validation defaults to enabled; call
+// WithStrictInputValidation(false) for an operation whose input the
+// service accepts but the stricter checks don't.
+func WithStrictInputValidation(enabled bool) func(*Options) {
+	return func(o *Options) {
+		o.StrictInputValidation = aws.Bool(enabled)
+	}
+}
+
+// strictInputValidationEnabled reports whether validateOpStrictInput's
+// extra checks should run: on by default, since StrictInputValidation is a
+// *bool and only WithStrictInputValidation ever sets it, so a caller who
+// never touches this option still gets the stricter validation the doc
+// comment above promises.
+func (o Options) strictInputValidationEnabled() bool {
+	return o.StrictInputValidation == nil || *o.StrictInputValidation
+}